@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/registry"
+)
+
+// platformInfo describes a registered platform.
+type platformInfo struct {
+	Names            []string          `json:"names"`
+	Interfaces       []string          `json:"interfaces"`
+	DefaultPath      string            `json:"default_path"`
+	ReplacementChars map[string]string `json:"replacement_chars"`
+}
+
+// platformsInfo describes all registered platforms and platform factories.
+type platformsInfo struct {
+	Platforms []platformInfo `json:"platforms"`
+	Factories []string       `json:"factories,omitempty"`
+}
+
+// sourceParamInfo describes a source configuration parameter.
+type sourceParamInfo struct {
+	Name        string `json:"name"`
+	Short       string `json:"short,omitempty"`
+	Description string `json:"description"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+	Bool        bool   `json:"bool"`
+}
+
+// sourceInfo describes a registered source.
+type sourceInfo struct {
+	Names       []string          `json:"names"`
+	Description string            `json:"description"`
+	Params      []sourceParamInfo `json:"params"`
+}
+
+func newPlatformInfo(p goloc.Platform) platformInfo {
+	interfaces := []string{}
+	for _, i := range registry.PlatformInterfaces() {
+		if reflect.TypeOf(p).Implements(i) {
+			interfaces = append(interfaces, i.Name())
+		}
+	}
+
+	return platformInfo{
+		Names:            p.Names(),
+		Interfaces:       interfaces,
+		DefaultPath:      p.LocalizationFilePath("<lang>", "", ""),
+		ReplacementChars: p.ReplacementChars(),
+	}
+}
+
+func describePlatforms(w io.Writer, name string, asJSON bool) error {
+	info := platformsInfo{Factories: registry.PlatformFactoryPrefixes()}
+	if name != "" {
+		p, err := registry.ResolvePlatform(name, nil)
+		if err != nil {
+			return err
+		}
+		info.Platforms = []platformInfo{newPlatformInfo(p)}
+		info.Factories = nil
+	} else {
+		for _, p := range registry.Platforms() {
+			info.Platforms = append(info.Platforms, newPlatformInfo(p))
+		}
+	}
+
+	if asJSON {
+		return writeJSON(w, info)
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Names", "Interfaces", "Default path", "Replacement chars"})
+	for _, p := range info.Platforms {
+		table.Append([]string{
+			strings.Join(p.Names, ", "),
+			strings.Join(p.Interfaces, ", "),
+			p.DefaultPath,
+			formatReplacementChars(p.ReplacementChars),
+		})
+	}
+	table.Render()
+
+	if len(info.Factories) > 0 {
+		var factories []string
+		for _, prefix := range info.Factories {
+			factories = append(factories, prefix+":<argument>")
+		}
+		_, err := fmt.Fprintf(w, "\nCustom platforms: %v\n", strings.Join(factories, ", "))
+		return err
+	}
+	return nil
+}
+
+func describeSources(w io.Writer, name string, asJSON bool) error {
+	var info []sourceInfo
+	for _, f := range registry.Sources() {
+		if name != "" && !contains(f.Names(), name) {
+			continue
+		}
+		var params []sourceParamInfo
+		for _, p := range f.Params() {
+			param := sourceParamInfo{Name: p.Name, Description: p.Description, Default: p.Default, Required: p.Required, Bool: p.Bool}
+			if p.Short != 0 {
+				param.Short = string(p.Short)
+			}
+			params = append(params, param)
+		}
+		info = append(info, sourceInfo{Names: f.Names(), Description: f.Description(), Params: params})
+	}
+	if name != "" && len(info) == 0 {
+		return fmt.Errorf(`"%v" is not a supported source`, name)
+	}
+
+	if asJSON {
+		return writeJSON(w, info)
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Names", "Description", "Parameters"})
+	for _, s := range info {
+		var params []string
+		for _, p := range s.Params {
+			param := "--" + p.Name
+			if p.Required {
+				param += " (required)"
+			}
+			if p.Default != "" {
+				param += fmt.Sprintf(" [%v]", p.Default)
+			}
+			params = append(params, param)
+		}
+		table.Append([]string{strings.Join(s.Names, ", "), s.Description, strings.Join(params, ", ")})
+	}
+	table.Render()
+	return nil
+}
+
+func formatReplacementChars(chars map[string]string) string {
+	var pairs []string
+	for orig, repl := range chars {
+		pairs = append(pairs, fmt.Sprintf("%v → %v", strconv.Quote(orig), strconv.Quote(repl)))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
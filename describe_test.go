@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// fallbackPlatform exposes only the goloc.Platform methods of the wrapped platform and implements FallbackStringWriter.
+type fallbackPlatform struct {
+	goloc.Platform
+}
+
+func (fallbackPlatform) FallbackString(args *goloc.LocalizedStringArgs) string {
+	return ""
+}
+
+func TestPlatformInfoInterfaces(t *testing.T) {
+	json := registry.GetPlatform("json")
+	assert.Empty(t, newPlatformInfo(struct{ goloc.Platform }{json}).Interfaces)
+	assert.Equal(t, []string{"FallbackStringWriter"}, newPlatformInfo(fallbackPlatform{json}).Interfaces)
+	assert.Contains(t, newPlatformInfo(registry.GetPlatform("android")).Interfaces, "ConfigurablePlatform")
+}
+
+func TestPlatformInterfacesAreInterfaces(t *testing.T) {
+	for _, i := range registry.PlatformInterfaces() {
+		assert.Equal(t, reflect.Interface, i.Kind(), i.String())
+	}
+}
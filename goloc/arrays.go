@@ -0,0 +1,112 @@
+package goloc
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// arrayKeyRegexp matches keys of the string array items, e.g. "colors[0]".
+var arrayKeyRegexp = regexp.MustCompile(`^(.*?)\s*\[(\d+)\]$`)
+
+// ArrayWriter can be implemented by a platform to support string arrays defined by rows with "key[0]", "key[1]", ...
+// keys. Items of an array are passed in LocalizedStringArgs.Items.
+type ArrayWriter interface {
+	// Returns an actual string array binding for a given language. Newlines must be included here if localization format requires them.
+	LocalizedArray(args *LocalizedStringArgs) string
+}
+
+// arrayItems collects the rows of a string array.
+type arrayItems struct {
+	key Key
+	// cell containing the key of the first item row.
+	cell  Cell
+	items map[int]arrayItem
+}
+
+type arrayItem struct {
+	cell  Cell
+	line  int
+	row   []RawCell
+	langs langColumns
+}
+
+func newArrayItems(key Key, cell Cell) *arrayItems {
+	return &arrayItems{key: key, cell: cell, items: map[int]arrayItem{}}
+}
+
+// add registers an item row of the array. An index defined more than once is resolved the same way as a duplicate key
+// in keys.
+func (a *arrayItems) add(keys *keySet, index int, sourceKey Key, cell Cell, line int, row []RawCell, langs langColumns) (warning error, err error) {
+	if item, ok := a.items[index]; ok {
+		keep, warning, err := keys.resolve(&duplicateKeyError{cell: cell, firstCell: item.cell, key: sourceKey})
+		if !keep {
+			return warning, err
+		}
+	}
+	a.items[index] = arrayItem{cell: cell, line: line, row: row, langs: langs}
+	return nil, nil
+}
+
+// localizations returns the items of the array for each language along with the localized values (items separated by
+// newlines). Items must have consecutive indices starting from 0, each language must either have all the items or none
+// of them (which is reported as a missing localization).
+func (a *arrayItems) localizations(
+	platform Platform,
+	errorIfMissing bool,
+	emptyLocalizationRegexp *regexp.Regexp,
+) (keyLoc map[Lang]string, items map[Lang][]string, warnings []error, error error) {
+	for i := 0; i < len(a.items); i++ {
+		if _, ok := a.items[i]; !ok {
+			error = &arrayItemMissingError{cell: a.cell, key: a.key, index: i}
+			return
+		}
+	}
+
+	first := a.items[0]
+	var columns []int
+	for i := range first.langs {
+		columns = append(columns, i)
+	}
+	sort.Ints(columns)
+
+	keyLoc = map[Lang]string{}
+	items = map[Lang][]string{}
+	for _, col := range columns {
+		lang := first.langs[col]
+
+		var values []string
+		var missing []Cell
+		for i := 0; i < len(a.items); i++ {
+			item := a.items[i]
+			for c, l := range item.langs {
+				if l != lang {
+					continue
+				}
+				if c < len(item.row) && !emptyLocalizationRegexp.MatchString(strings.TrimSpace(item.row[c])) {
+					values = append(values, WithReplacedSpecialChars(platform, strings.TrimSpace(item.row[c])))
+				} else {
+					missing = append(missing, *NewCell(item.cell.tab, uint(item.line), uint(c)))
+				}
+			}
+		}
+
+		switch {
+		case len(values) == 0:
+			keyLoc[lang] = ""
+			missingError := newLocalizationMissingError(a.cell.tab, first.line, col, a.key, lang)
+			if errorIfMissing {
+				error = missingError
+				return
+			}
+			warnings = append(warnings, missingError)
+		case len(missing) > 0:
+			error = &arrayLengthError{cell: missing[0], key: a.key, lang: lang, length: len(values), expected: len(a.items)}
+			return
+		default:
+			items[lang] = values
+			keyLoc[lang] = strings.Join(values, "\n")
+		}
+	}
+	return
+}
@@ -18,6 +18,11 @@ func NewCell(tab string, row uint, column uint) *Cell {
 	return &Cell{tab: tab, row: row, column: column}
 }
 
+// Tab returns a name of the tab containing the cell.
+func (c Cell) Tab() string {
+	return c.tab
+}
+
 func (c Cell) String() string {
 	return fmt.Sprintf(`%v!%v%v`, c.tab, utils.ColumnName(c.column), c.row)
 }
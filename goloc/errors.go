@@ -42,6 +42,13 @@ type formatArgsDifferentError struct {
 	lang string
 }
 
+type formatArgPositionError struct {
+	cell     Cell
+	arg      string
+	position int
+	reason   string
+}
+
 type wrongValueTypeError struct {
 	cell Cell
 }
@@ -69,11 +76,73 @@ type keyMissingError struct {
 	cell Cell
 }
 
+type duplicateKeyError struct {
+	cell      Cell
+	firstCell Cell
+	key       Key
+}
+
 type formatNotFoundError struct {
 	cell       Cell
 	formatName string
 }
 
+type invalidKeyError struct {
+	cell         Cell
+	key          Key
+	platformName string
+	reason       error
+}
+
+type keyCollisionError struct {
+	cell           Cell
+	firstCell      Cell
+	key            Key
+	sourceKey      Key
+	firstSourceKey Key
+}
+
+type messageSyntaxError struct {
+	cell   Cell
+	reason error
+}
+
+type messageNotSupportedError struct {
+	cell         Cell
+	platformName string
+}
+
+type messageInvalidError struct {
+	cell         Cell
+	platformName string
+	reason       error
+}
+
+type arrayNotSupportedError struct {
+	cell         Cell
+	platformName string
+	key          Key
+}
+
+type arrayItemMissingError struct {
+	cell  Cell
+	key   Key
+	index int
+}
+
+type arrayLengthError struct {
+	cell     Cell
+	key      Key
+	lang     string
+	length   int
+	expected int
+}
+
+type unknownPlatformError struct {
+	cell         Cell
+	platformName string
+}
+
 func newFormatArgsDifferentError(tab string, row int, col int, key Key, lang string) *formatArgsDifferentError {
 	return &formatArgsDifferentError{
 		cell: *NewCell(tab, uint(row), uint(col)),
@@ -128,6 +197,10 @@ func (e *formatArgsDifferentError) Error() string {
 	return fmt.Sprintf(`%v: format arguments must be the same for each language`, e.cell)
 }
 
+func (e *formatArgPositionError) Error() string {
+	return fmt.Sprintf(`%v: invalid position %v of "%v" argument (%v)`, e.cell, e.position, e.arg, e.reason)
+}
+
 func (e *wrongValueTypeError) Error() string {
 	return fmt.Sprintf(`%v: wrong value type`, e.cell)
 }
@@ -155,3 +228,43 @@ func (e *keyMissingError) Error() string {
 func (e *formatNotFoundError) Error() string {
 	return fmt.Sprintf(`%v: no such format - "%v"`, e.cell, e.formatName)
 }
+
+func (e *duplicateKeyError) Error() string {
+	return fmt.Sprintf(`%v: "%v" key is already defined at %v`, e.cell, e.key, e.firstCell)
+}
+
+func (e *unknownPlatformError) Error() string {
+	return fmt.Sprintf(`%v: unknown platform "%v"`, e.cell, e.platformName)
+}
+
+func (e *invalidKeyError) Error() string {
+	return fmt.Sprintf(`%v: key "%v" is invalid for platform "%v" (%v)`, e.cell, e.key, e.platformName, e.reason)
+}
+
+func (e *keyCollisionError) Error() string {
+	return fmt.Sprintf(`%v: "%v" key becomes "%v" after the normalization, which collides with "%v" defined at %v`, e.cell, e.sourceKey, e.key, e.firstSourceKey, e.firstCell)
+}
+
+func (e *messageSyntaxError) Error() string {
+	return fmt.Sprintf(`%v: invalid ICU message (%v)`, e.cell, e.reason)
+}
+
+func (e *messageNotSupportedError) Error() string {
+	return fmt.Sprintf(`%v: ICU messages with select, plural or selectordinal arguments aren't supported by "%v" platform`, e.cell, e.platformName)
+}
+
+func (e *messageInvalidError) Error() string {
+	return fmt.Sprintf(`%v: ICU message can't be written for platform "%v" (%v)`, e.cell, e.platformName, e.reason)
+}
+
+func (e *arrayNotSupportedError) Error() string {
+	return fmt.Sprintf(`%v: string arrays aren't supported by "%v" platform, "%v" is written as a plain key`, e.cell, e.platformName, e.key)
+}
+
+func (e *arrayItemMissingError) Error() string {
+	return fmt.Sprintf(`%v: "%v" array has no item with index %v`, e.cell, e.key, e.index)
+}
+
+func (e *arrayLengthError) Error() string {
+	return fmt.Sprintf(`%v: "%v" array has %v of %v items for "%v" language, each language must have the same number of items`, e.cell, e.key, e.length, e.expected, e.lang)
+}
@@ -1,10 +1,38 @@
 package goloc
 
-import (
-	"github.com/s0nerik/goloc/goloc/re"
-	"strings"
+import "strings"
+
+// Built-in formats of the inline typed placeholders (e.g. "{count, number}" or "{price, number, currency}").
+const (
+	InlineFormatString   = `string`
+	InlineFormatNumber   = `number`
+	InlineFormatInteger  = `number, integer`
+	InlineFormatDecimal  = `number, decimal`
+	InlineFormatCurrency = `number, currency`
 )
 
+// InlineFormatter can be implemented by a platform to support inline typed placeholders without specifying
+// their formats in the formats tab.
+type InlineFormatter interface {
+	// Returns the platform formats for the built-in format names (InlineFormatString, InlineFormatNumber, etc.).
+	InlineFormats() Formats
+}
+
+// WithInlineFormats returns formats extended with the built-in formats of the platform if it's an InlineFormatter.
+// Formats from the formats tab take precedence over the built-in ones.
+func WithInlineFormats(platform Platform, formats Formats) Formats {
+	result := Formats{}
+	if f, ok := platform.(InlineFormatter); ok {
+		for name, format := range f.InlineFormats() {
+			result[name] = format
+		}
+	}
+	for name, format := range formats {
+		result[name] = format
+	}
+	return result
+}
+
 // ParseFormats parses formats given the raw table data and returns, if successful, mappings
 // to the actual platform format for each format name.
 func ParseFormats(
@@ -108,11 +136,12 @@ func columnIndices(
 	return
 }
 
+// FormatArgs returns format names of all placeholders in a string.
 func FormatArgs(str string) (args []FormatKey) {
-	matches := re.FormatRegexp().FindAllStringSubmatch(str, -1)
+	placeholders, _ := placeholders(str)
 
-	for _, m := range matches {
-		args = append(args, m[1])
+	for _, p := range placeholders {
+		args = append(args, p.format)
 	}
 
 	return args
@@ -126,4 +126,23 @@ func TestFormatsFormatValidation(t *testing.T) {
 	platform.AssertCalled(t, "ValidateFormat", "s")
 	platform.AssertCalled(t, "ValidateFormat", "%s")
 	platform.AssertNumberOfCalls(t, "ValidateFormat", 2)
-}
\ No newline at end of file
+}
+
+type inlineFormatterMockPlatform struct {
+	*mockPlatform
+}
+
+func (inlineFormatterMockPlatform) InlineFormats() Formats {
+	return Formats{
+		InlineFormatString: "s",
+		InlineFormatNumber: "d",
+	}
+}
+
+func TestWithInlineFormats(t *testing.T) {
+	platform := inlineFormatterMockPlatform{newMockPlatform(nil)}
+	formats := WithInlineFormats(platform, Formats{"x": "s", InlineFormatNumber: "ld"})
+	assert.Equal(t, Formats{"x": "s", InlineFormatString: "s", InlineFormatNumber: "ld"}, formats)
+
+	assert.Equal(t, Formats{"x": "s"}, WithInlineFormats(newMockPlatform(nil), Formats{"x": "s"}))
+}
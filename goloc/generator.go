@@ -0,0 +1,38 @@
+package goloc
+
+// OutputFile represents a single file produced by a Generator.
+type OutputFile struct {
+	Path     string `json:"path"`
+	Contents string `json:"contents"`
+}
+
+// GenerateArgs encapsulates arguments for a generate function
+type GenerateArgs struct {
+	Localizations           Localizations
+	Formats                 Formats
+	FormatArgs              LocalizationFormatArgs
+	Meta                    LocalizationMeta
+	ResDir                  ResDir
+	DefaultLocalization     Lang
+	DefaultLocalizationPath string
+	// Namespaces of the keys (see KeyNamespaces).
+	Namespaces LocalizationNamespaces
+	// Hash is a content hash of the source data (see Lock).
+	Hash string
+}
+
+// Generator is implemented by platforms that produce the resulting files on their own instead of
+// providing a header, localized strings and a footer for each language.
+// Paths of the returned files must be relative to the resources directory.
+type Generator interface {
+	Generate(args GenerateArgs) ([]OutputFile, error)
+}
+
+// ExtraFilesGenerator is implemented by platforms that produce additional files along with the localization files
+// (e.g. plural rules or helper sources). Unlike the files written by a Postprocessor, these are written together with
+// the localization files, so they're listed in the WriteSummary and recorded in the manifest.
+// Paths of the returned files are used as is, the same way as the ones returned by Platform.LocalizationFilePath.
+// Files outside of the resources directory aren't recorded in the manifest, so they're never pruned.
+type ExtraFilesGenerator interface {
+	ExtraFiles(args GenerateArgs) ([]OutputFile, error)
+}
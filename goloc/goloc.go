@@ -10,8 +10,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/s0nerik/goloc/goloc/icu"
 )
 
 type RawCell = string
@@ -37,7 +39,43 @@ type Formats = map[FormatKey]string
 // ResDir represents a resources directory path.
 type ResDir = string
 
-func fetchEverythingRaw(source Source) (rawFormats, rawLocalizations [][]string, err error) {
+// Namespace represents a name of a group of localized strings that are written into separate localization files.
+// Empty namespace represents the default group.
+type Namespace = string
+
+// LocalizationNamespaces represents a mapping between a localized string key and its namespace.
+type LocalizationNamespaces map[Key]Namespace
+
+// KeyMeta contains additional information about a localized string key.
+type KeyMeta struct {
+	// Cell containing the key.
+	Cell Cell
+	// SourceKey is the key as it's specified in the sheet (before the normalization, see NormalizeKey).
+	SourceKey Key
+	// Description provides a context for translators and developers. Empty if not specified.
+	Description string
+	// Messages are the parsed localized strings for each language if the key is an ICU message with select, plural or
+	// selectordinal arguments, nil otherwise.
+	Messages map[Lang]icu.Message
+	// MessageArgs are arguments of the ICU messages in the order of their first occurrence in the first language.
+	MessageArgs []icu.Arg
+	// Items of the string array for each language if the key is an array (see ArrayWriter), nil otherwise.
+	Items map[Lang][]string
+}
+
+// LocalizationMeta represents a mapping between a localized string key and its additional information.
+type LocalizationMeta map[Key]*KeyMeta
+
+const (
+	// SplitByNone writes all localized strings for a language into a single file.
+	SplitByNone = `none`
+	// SplitByTab writes localized strings from each localizations tab into a separate file.
+	SplitByTab = `tab`
+	// SplitByPrefix writes localized strings into separate files by the key prefix before the namespace separator.
+	SplitByPrefix = `prefix`
+)
+
+func fetchEverythingRaw(source Source) (rawFormats [][]string, localizationTabs []Tab, err error) {
 	var formatsError error
 	var localizationsError error
 
@@ -48,7 +86,12 @@ func fetchEverythingRaw(source Source) (rawFormats, rawLocalizations [][]string,
 	}()
 	go func() {
 		defer wg.Done()
-		rawLocalizations, localizationsError = source.Localizations()
+		if s, ok := source.(MultiTabSource); ok {
+			localizationTabs, localizationsError = s.LocalizationTabs()
+			return
+		}
+		rawLocalizations, err := source.Localizations()
+		localizationTabs, localizationsError = []Tab{{Name: source.LocalizationsDocumentName(), Rows: rawLocalizations}}, err
 	}()
 
 	wg.Add(2)
@@ -58,42 +101,88 @@ func fetchEverythingRaw(source Source) (rawFormats, rawLocalizations [][]string,
 		return nil, nil, fmt.Errorf(`can't load formats (%w)`, formatsError)
 	}
 	if localizationsError != nil {
-		return nil, nil, fmt.Errorf(`can't load localizations (%w)`, formatsError)
+		return nil, nil, fmt.Errorf(`can't load localizations (%w)`, localizationsError)
 	}
 
 	return
 }
 
+// RunOptions configures the generation of the localization files by Run.
+type RunOptions struct {
+	// ResDir is a path to the resources folder.
+	ResDir string
+	// KeyColumn is a title of the key column.
+	KeyColumn string
+	// DescriptionColumn is a title of the optional description column.
+	DescriptionColumn string
+	// PlatformsColumn is a title of the optional column listing the platforms each row is written for.
+	PlatformsColumn string
+	// KnownPlatforms are names of all supported platforms, used to report the unknown names in the platforms column.
+	KnownPlatforms []string
+	// KeyCase is a case the keys are converted into (see NormalizeKey).
+	KeyCase string
+	// OnDuplicate is one of OnDuplicateError, OnDuplicateWarn, OnDuplicateFirst or OnDuplicateLast.
+	OnDuplicate string
+	// IgnoreKeyCase makes keys which only differ in case duplicates.
+	IgnoreKeyCase bool
+	// FormatNameColumn is a title of the format name column.
+	FormatNameColumn string
+	// DefaultFormatName is a name of the format used in place of "{}".
+	DefaultFormatName string
+	// DefaultLocalization is a default localization language (e.g. "en").
+	DefaultLocalization string
+	// DefaultLocalizationPath is a path to the file the default localization is written into. Empty to use the
+	// platform path.
+	DefaultLocalizationPath string
+	// StopOnMissing makes missing localizations errors instead of warnings.
+	StopOnMissing bool
+	// ReportMissingLocalizations only prints the missing localizations without writing the localization files.
+	ReportMissingLocalizations bool
+	// EmptyLocalizationMatch matches the localized strings which are considered missing.
+	EmptyLocalizationMatch *regexp.Regexp
+	// SplitBy is one of SplitByNone, SplitByTab or SplitByPrefix.
+	SplitBy string
+	// NamespaceSeparator separates the key prefix from the rest of the key when splitting by prefix.
+	NamespaceSeparator string
+	// LockFilePath is a path to the lock file (see Lock). Empty to not write the lock file.
+	LockFilePath string
+	// Version of goloc recorded in the lock file.
+	Version string
+	// Prune removes previously generated files which aren't produced anymore.
+	Prune bool
+	// Merge merges localized strings into the existing files (see Merger).
+	Merge bool
+}
+
 // Run launches the actual process of fetching, parsing and writing the localization files.
-func Run(
-	source Source,
-	platform Platform,
-	resDir string,
-	keyColumn string,
-	formatNameColumn string,
-	defaultLocalization string,
-	defaultLocalizationPath string,
-	stopOnMissing bool,
-	reportMissingLocalizations bool,
-	defFormatName string,
-	emptyLocalizationMatch *regexp.Regexp,
-) error {
-	rawFormats, rawLocalizations, err := fetchEverythingRaw(source)
+func Run(source Source, platform Platform, options RunOptions) error {
+	if _, ok := platform.(Merger); options.Merge && !ok {
+		return &mergeNotSupportedError{platform: platform.Names()[0]}
+	}
+
+	rawFormats, localizationTabs, err := fetchEverythingRaw(source)
 	if err != nil {
 		return fmt.Errorf(`can't fetch data, reason: %w`, err)
 	}
 
-	formats, err := ParseFormats(rawFormats, platform, source.FormatsDocumentName(), formatNameColumn, defFormatName)
-	if err != nil {
-		return err
+	lock := NewLock(rawFormats, localizationTabs)
+
+	// Formats tab is optional if only the inline typed placeholders are used
+	formats := Formats{}
+	if len(rawFormats) > 0 {
+		formats, err = ParseFormats(rawFormats, platform, source.FormatsDocumentName(), options.FormatNameColumn, options.DefaultFormatName)
+		if err != nil {
+			return err
+		}
 	}
+	formats = WithInlineFormats(platform, formats)
 
-	localizations, fArgs, warn, err := ParseLocalizations(rawLocalizations, platform, formats, source.LocalizationsDocumentName(), keyColumn, stopOnMissing, emptyLocalizationMatch)
+	localizations, fArgs, meta, warn, err := ParseLocalizationTabs(localizationTabs, platform, formats, options.KeyColumn, options.DescriptionColumn, options.PlatformsColumn, options.KnownPlatforms, options.KeyCase, options.OnDuplicate, options.IgnoreKeyCase, options.StopOnMissing, options.EmptyLocalizationMatch)
 	if err != nil {
 		return err
 	}
 
-	if reportMissingLocalizations {
+	if options.ReportMissingLocalizations {
 		reportMissingLanguages(warn)
 		return errors.New("found missing localizations")
 	}
@@ -102,10 +191,15 @@ func Run(
 		log.Println(w)
 	}
 
+	namespaces, err := KeyNamespaces(localizations, meta, options.SplitBy, options.NamespaceSeparator)
+	if err != nil {
+		return err
+	}
+
 	// Make sure we can access resources dir
-	if _, err := os.Stat(resDir); err != nil {
+	if _, err := os.Stat(options.ResDir); err != nil {
 		if os.IsNotExist(err) {
-			err := os.MkdirAll(resDir, 0755)
+			err := os.MkdirAll(options.ResDir, 0755)
 			if err != nil {
 				return err
 			}
@@ -115,37 +209,93 @@ func Run(
 	}
 
 	if p, ok := platform.(Preprocessor); ok {
-		err := p.Preprocess(PreprocessArgs{ResDir: resDir, Localizations: localizations, Formats: formats, FormatArgs: fArgs, DefaultLocalization: defaultLocalization})
+		err := p.Preprocess(PreprocessArgs{ResDir: options.ResDir, Localizations: localizations, Formats: formats, FormatArgs: fArgs, Meta: meta, DefaultLocalization: options.DefaultLocalization})
 		if err != nil {
 			return err
 		}
 	}
 
-	err = WriteLocalizations(platform, resDir, localizations, fArgs, defaultLocalization, defaultLocalizationPath)
-	if err != nil {
-		return fmt.Errorf(`can't write localizations, reason: %w`, err)
+	generateArgs := GenerateArgs{
+		Localizations:           localizations,
+		Formats:                 formats,
+		FormatArgs:              fArgs,
+		Meta:                    meta,
+		ResDir:                  options.ResDir,
+		DefaultLocalization:     options.DefaultLocalization,
+		DefaultLocalizationPath: options.DefaultLocalizationPath,
+		Namespaces:              namespaces,
+		Hash:                    lock.Hash,
+	}
+
+	var summary *WriteSummary
+	if g, ok := platform.(Generator); ok {
+		files, err := g.Generate(generateArgs)
+		if err != nil {
+			return fmt.Errorf(`can't generate localizations, reason: %w`, err)
+		}
+		summary, err = WriteGeneratedFiles(options.ResDir, files)
+		if err != nil {
+			return fmt.Errorf(`can't write localizations, reason: %w`, err)
+		}
+	} else {
+		var extra []OutputFile
+		if g, ok := platform.(ExtraFilesGenerator); ok {
+			if extra, err = g.ExtraFiles(generateArgs); err != nil {
+				return fmt.Errorf(`can't generate localizations, reason: %w`, err)
+			}
+		}
+		summary, err = WriteLocalizations(platform, options.ResDir, localizations, fArgs, meta, namespaces, options.DefaultLocalization, options.DefaultLocalizationPath, lock.Hash, options.Merge, extra)
+		if err != nil {
+			return fmt.Errorf(`can't write localizations, reason: %w`, err)
+		}
 	}
 
 	if p, ok := platform.(Postprocessor); ok {
-		err := p.Postprocess(PostprocessArgs{ResDir: resDir, Localizations: localizations, Formats: formats, FormatArgs: fArgs, DefaultLocalization: defaultLocalization})
+		err := p.Postprocess(PostprocessArgs{ResDir: options.ResDir, Localizations: localizations, Formats: formats, FormatArgs: fArgs, Meta: meta, DefaultLocalization: options.DefaultLocalization})
 		if err != nil {
 			return err
 		}
 	}
 
+	stale, err := updateManifest(options.ResDir, summary, options.Prune)
+	if err != nil {
+		return fmt.Errorf(`can't update the list of generated files, reason: %w`, err)
+	}
+	if len(stale) > 0 {
+		log.Printf(`%d previously generated files aren't produced anymore, specify "--prune" to remove them: %v`, len(stale), strings.Join(stale, ", "))
+	}
+
+	log.Printf("Localization files: %v", summary)
+
+	if options.LockFilePath != "" {
+		lock.Keys = countKeys(localizations)
+		lock.Version = options.Version
+		lock.GeneratedAt = time.Now().UTC().Truncate(time.Second)
+		if err := WriteLock(options.LockFilePath, lock); err != nil {
+			return fmt.Errorf(`can't write lock file, reason: %w`, err)
+		}
+	}
+
 	return nil
 }
 
 func reportMissingLanguages(warnings []error) {
-	rowWarnings := map[uint][]*localizationMissingError{}
+	type tabRow struct {
+		tab string
+		row uint
+	}
+
+	tabs := map[string]bool{}
+	rowWarnings := map[tabRow][]*localizationMissingError{}
 	for _, w := range warnings {
 		if w, ok := w.(*localizationMissingError); ok {
-			rowWarnings[w.cell.row] = append(rowWarnings[w.cell.row], w)
+			tabs[w.cell.tab] = true
+			rowWarnings[tabRow{w.cell.tab, w.cell.row}] = append(rowWarnings[tabRow{w.cell.tab, w.cell.row}], w)
 		}
 	}
 
 	type kv struct {
-		row      uint
+		tabRow   tabRow
 		warnings []*localizationMissingError
 	}
 
@@ -155,12 +305,20 @@ func reportMissingLanguages(warnings []error) {
 	}
 
 	sort.Slice(sortedRowWarnings, func(i, j int) bool {
-		return sortedRowWarnings[i].row < sortedRowWarnings[j].row
+		if sortedRowWarnings[i].tabRow.tab != sortedRowWarnings[j].tabRow.tab {
+			return sortedRowWarnings[i].tabRow.tab < sortedRowWarnings[j].tabRow.tab
+		}
+		return sortedRowWarnings[i].tabRow.row < sortedRowWarnings[j].tabRow.row
 	})
 
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Row", "Key", "Missing localizations"})
+	if len(tabs) > 1 {
+		table.SetHeader([]string{"Tab", "Row", "Key", "Missing localizations"})
+	} else {
+		table.SetHeader([]string{"Row", "Key", "Missing localizations"})
+	}
 	for _, kv := range sortedRowWarnings {
+		tab := kv.warnings[0].cell.tab
 		row := kv.warnings[0].cell.row
 		key := kv.warnings[0].key
 
@@ -169,7 +327,11 @@ func reportMissingLanguages(warnings []error) {
 			missingLanguages = append(missingLanguages, w.lang)
 		}
 
-		table.Append([]string{strconv.Itoa(int(row)), key, strings.Join(missingLanguages, ",")})
+		if len(tabs) > 1 {
+			table.Append([]string{tab, strconv.Itoa(int(row)), key, strings.Join(missingLanguages, ",")})
+		} else {
+			table.Append([]string{strconv.Itoa(int(row)), key, strings.Join(missingLanguages, ",")})
+		}
 	}
 	table.Render()
 }
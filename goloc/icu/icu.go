@@ -0,0 +1,459 @@
+// Package icu implements a parser of the ICU MessageFormat syntax (https://unicode-org.github.io/icu/userguide/format_parse/messages/)
+// used for localized strings with select, plural and selectordinal arguments.
+package icu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Complex argument types.
+const (
+	TypeSelect        = `select`
+	TypePlural        = `plural`
+	TypeSelectOrdinal = `selectordinal`
+)
+
+// OtherCase is a key of the case used when no other case matches. It's required in each complex argument.
+const OtherCase = `other`
+
+var pluralKeywords = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true, OtherCase: true}
+
+// Message is a parsed ICU message.
+type Message []Node
+
+// Node is a part of a message: *Text, *Placeholder, *Pound or *Select.
+type Node interface {
+	write(b *strings.Builder, inPlural bool)
+}
+
+// Text is a literal text.
+type Text struct {
+	Value string
+}
+
+// Placeholder is a simple argument, e.g. "{name}" or "{count, number, integer}".
+type Placeholder struct {
+	Name string
+	// Type of the argument (e.g. "number"). Empty if not specified.
+	Type string
+	// Style of the argument (e.g. "integer"). Empty if not specified.
+	Style string
+}
+
+// Pound is a "#" within a plural or selectordinal case, which is replaced with the formatted number.
+type Pound struct{}
+
+// Select is a complex argument, e.g. "{gender, select, male {He} female {She} other {They}}".
+type Select struct {
+	Name string
+	// Type is one of TypeSelect, TypePlural or TypeSelectOrdinal.
+	Type string
+	// Offset is subtracted from the number before choosing a plural case. Zero for select arguments.
+	Offset int
+	Cases  []*Case
+}
+
+// Case is a variant of a complex argument message.
+type Case struct {
+	// Key is either a keyword (e.g. "male" or "one") or an explicit value (e.g. "=0").
+	Key     string
+	Message Message
+}
+
+// Arg describes an argument of a message.
+type Arg struct {
+	Name string
+	// Type of the argument: one of TypeSelect, TypePlural, TypeSelectOrdinal or a simple argument type (e.g. "number").
+	// Empty for the untyped simple arguments.
+	Type string
+}
+
+// SyntaxError describes a syntax error in a message.
+type SyntaxError struct {
+	// Offset is a 0-based index of the character at which the error occurred.
+	Offset int
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf(`%v at position %v`, e.Reason, e.Offset+1)
+}
+
+// Case returns a case with a given key or nil if there's no such case.
+func (s *Select) Case(key string) *Case {
+	for _, c := range s.Cases {
+		if c.Key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// IsComplex reports whether a message contains select, plural or selectordinal arguments.
+func (m Message) IsComplex() bool {
+	for _, n := range m {
+		if _, ok := n.(*Select); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Args returns the arguments of a message, including the ones in the nested messages, in the order of their first occurrence.
+func (m Message) Args() (args []Arg) {
+	seen := map[string]bool{}
+	var collect func(m Message)
+	collect = func(m Message) {
+		for _, n := range m {
+			switch n := n.(type) {
+			case *Placeholder:
+				if !seen[n.Name] {
+					seen[n.Name] = true
+					args = append(args, Arg{Name: n.Name, Type: n.Type})
+				}
+			case *Select:
+				if !seen[n.Name] {
+					seen[n.Name] = true
+					args = append(args, Arg{Name: n.Name, Type: n.Type})
+				}
+				for _, c := range n.Cases {
+					collect(c.Message)
+				}
+			}
+		}
+	}
+	collect(m)
+	return
+}
+
+// String returns the message in the ICU MessageFormat syntax.
+func (m Message) String() string {
+	var b strings.Builder
+	m.write(&b, false)
+	return b.String()
+}
+
+func (m Message) write(b *strings.Builder, inPlural bool) {
+	for _, n := range m {
+		n.write(b, inPlural)
+	}
+}
+
+func (t *Text) write(b *strings.Builder, inPlural bool) {
+	special := "{}"
+	if inPlural {
+		special += "#"
+	}
+	runes := []rune(t.Value)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case strings.ContainsRune(special, r):
+			// Quote the whole run of special characters and apostrophes
+			b.WriteRune('\'')
+			for ; i < len(runes) && (runes[i] == '\'' || strings.ContainsRune(special, runes[i])); i++ {
+				if runes[i] == '\'' {
+					b.WriteString("''")
+				} else {
+					b.WriteRune(runes[i])
+				}
+			}
+			b.WriteRune('\'')
+			i--
+		case r == '\'':
+			// An apostrophe only needs to be doubled if it could start a quoted text
+			if i+1 == len(runes) || runes[i+1] == '\'' || strings.ContainsRune(special, runes[i+1]) {
+				b.WriteString("''")
+			} else {
+				b.WriteRune(r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+func (p *Placeholder) write(b *strings.Builder, inPlural bool) {
+	b.WriteString("{" + p.Name)
+	if p.Type != "" {
+		b.WriteString(", " + p.Type)
+	}
+	if p.Style != "" {
+		b.WriteString(", " + p.Style)
+	}
+	b.WriteString("}")
+}
+
+func (p *Pound) write(b *strings.Builder, inPlural bool) {
+	b.WriteString("#")
+}
+
+func (s *Select) write(b *strings.Builder, inPlural bool) {
+	b.WriteString(fmt.Sprintf("{%v, %v,", s.Name, s.Type))
+	if s.Offset != 0 {
+		b.WriteString(fmt.Sprintf(" offset:%v", s.Offset))
+	}
+	for _, c := range s.Cases {
+		b.WriteString(" " + c.Key + " {")
+		c.Message.write(b, inPlural || s.Type != TypeSelect)
+		b.WriteString("}")
+	}
+	b.WriteString("}")
+}
+
+// Parse parses a message in the ICU MessageFormat syntax.
+func Parse(str string) (Message, error) {
+	p := &parser{runes: []rune(str)}
+	m, err := p.message(false)
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.error(`unexpected "}"`)
+	}
+	return m, nil
+}
+
+type parser struct {
+	runes []rune
+	pos   int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.runes)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.runes[p.pos]
+}
+
+func (p *parser) error(reason string) error {
+	return &SyntaxError{Offset: p.pos, Reason: reason}
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && strings.ContainsRune(" \t\r\n", p.peek()) {
+		p.pos++
+	}
+}
+
+// identifier reads an argument name, a type or a case keyword.
+func (p *parser) identifier() string {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n{},'#=", p.peek()) {
+		p.pos++
+	}
+	return string(p.runes[start:p.pos])
+}
+
+// message reads a message until the closing "}" of the enclosing argument or the end of the input.
+func (p *parser) message(inPlural bool) (m Message, err error) {
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			m = append(m, &Text{Value: text.String()})
+			text.Reset()
+		}
+	}
+
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case r == '}':
+			flush()
+			return
+		case r == '{':
+			flush()
+			n, err := p.argument(inPlural)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, n)
+		case r == '#' && inPlural:
+			flush()
+			p.pos++
+			m = append(m, &Pound{})
+		case r == '\'':
+			p.pos++
+			next := p.peek()
+			switch {
+			case next == '\'':
+				text.WriteRune('\'')
+				p.pos++
+			case next == '{' || next == '}' || next == '#' && inPlural:
+				// Quoted literal text until the next single apostrophe
+				for {
+					if p.eof() {
+						return nil, p.error(`unterminated quoted text`)
+					}
+					r := p.peek()
+					p.pos++
+					if r == '\'' {
+						if p.peek() == '\'' {
+							text.WriteRune('\'')
+							p.pos++
+							continue
+						}
+						break
+					}
+					text.WriteRune(r)
+				}
+			default:
+				text.WriteRune('\'')
+			}
+		default:
+			text.WriteRune(r)
+			p.pos++
+		}
+	}
+	flush()
+	return
+}
+
+// argument reads an argument starting at "{". inPlural is true if the argument is nested in a plural or
+// selectordinal case.
+func (p *parser) argument(inPlural bool) (Node, error) {
+	start := p.pos
+	p.pos++
+	p.skipSpaces()
+	name := p.identifier()
+	if name == "" {
+		return nil, p.error(`argument name expected`)
+	}
+	p.skipSpaces()
+
+	switch p.peek() {
+	case '}':
+		p.pos++
+		return &Placeholder{Name: name}, nil
+	case ',':
+		p.pos++
+	default:
+		if p.eof() {
+			return nil, &SyntaxError{Offset: start, Reason: `unterminated argument`}
+		}
+		return nil, p.error(`"," or "}" expected`)
+	}
+
+	p.skipSpaces()
+	argType := p.identifier()
+	if argType == "" {
+		return nil, p.error(`argument type expected`)
+	}
+	p.skipSpaces()
+
+	switch argType {
+	case TypeSelect, TypePlural, TypeSelectOrdinal:
+		if p.peek() != ',' {
+			return nil, p.error(fmt.Sprintf(`"," expected after "%v"`, argType))
+		}
+		p.pos++
+		return p.complexArgument(start, name, argType, inPlural)
+	}
+
+	placeholder := &Placeholder{Name: name, Type: argType}
+	switch p.peek() {
+	case '}':
+		p.pos++
+		return placeholder, nil
+	case ',':
+		p.pos++
+	default:
+		if p.eof() {
+			return nil, &SyntaxError{Offset: start, Reason: `unterminated argument`}
+		}
+		return nil, p.error(`"," or "}" expected`)
+	}
+
+	styleStart := p.pos
+	for !p.eof() && p.peek() != '}' {
+		if p.peek() == '{' {
+			return nil, p.error(`unexpected "{" in argument style`)
+		}
+		p.pos++
+	}
+	if p.eof() {
+		return nil, &SyntaxError{Offset: start, Reason: `unterminated argument`}
+	}
+	placeholder.Style = strings.TrimSpace(string(p.runes[styleStart:p.pos]))
+	p.pos++
+	return placeholder, nil
+}
+
+// complexArgument reads cases of a select, plural or selectordinal argument.
+func (p *parser) complexArgument(start int, name string, argType string, inPlural bool) (Node, error) {
+	s := &Select{Name: name, Type: argType}
+	keys := map[string]bool{}
+
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return nil, &SyntaxError{Offset: start, Reason: `unterminated argument`}
+		}
+		if p.peek() == '}' {
+			break
+		}
+
+		keyStart := p.pos
+		var key string
+		if p.peek() == '=' {
+			p.pos++
+			number := p.identifier()
+			if _, err := strconv.Atoi(number); err != nil || argType == TypeSelect {
+				return nil, &SyntaxError{Offset: keyStart, Reason: fmt.Sprintf(`invalid case "=%v"`, number)}
+			}
+			key = "=" + number
+		} else {
+			key = p.identifier()
+			if key == "" {
+				return nil, p.error(`case keyword expected`)
+			}
+			if strings.HasPrefix(key, "offset:") && argType != TypeSelect && len(s.Cases) == 0 && s.Offset == 0 {
+				value := strings.TrimPrefix(key, "offset:")
+				if value == "" {
+					p.skipSpaces()
+					value = p.identifier()
+				}
+				offset, err := strconv.Atoi(value)
+				if err != nil || offset < 0 {
+					return nil, &SyntaxError{Offset: keyStart, Reason: fmt.Sprintf(`invalid offset "%v"`, key)}
+				}
+				s.Offset = offset
+				continue
+			}
+			if argType != TypeSelect && !pluralKeywords[key] {
+				return nil, &SyntaxError{Offset: keyStart, Reason: fmt.Sprintf(`invalid %v case "%v"`, argType, key)}
+			}
+		}
+		if keys[key] {
+			return nil, &SyntaxError{Offset: keyStart, Reason: fmt.Sprintf(`duplicate case "%v"`, key)}
+		}
+		keys[key] = true
+
+		p.skipSpaces()
+		if p.peek() != '{' {
+			return nil, p.error(fmt.Sprintf(`"{" expected after case "%v"`, key))
+		}
+		p.pos++
+		m, err := p.message(inPlural || argType != TypeSelect)
+		if err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, &SyntaxError{Offset: keyStart, Reason: fmt.Sprintf(`unterminated case "%v"`, key)}
+		}
+		p.pos++
+		s.Cases = append(s.Cases, &Case{Key: key, Message: m})
+	}
+
+	if !keys[OtherCase] {
+		return nil, &SyntaxError{Offset: start, Reason: fmt.Sprintf(`"%v" case is required in "%v" argument`, OtherCase, name)}
+	}
+	p.pos++
+	return s, nil
+}
@@ -0,0 +1,99 @@
+package icu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSimple(t *testing.T) {
+	m, err := Parse(`Hello, {name}! You have {count, number, integer} new '{messages}' and it''s fine`)
+	assert.NoError(t, err)
+	assert.Equal(t, Message{
+		&Text{Value: "Hello, "},
+		&Placeholder{Name: "name"},
+		&Text{Value: "! You have "},
+		&Placeholder{Name: "count", Type: "number", Style: "integer"},
+		&Text{Value: " new {messages} and it's fine"},
+	}, m)
+	assert.False(t, m.IsComplex())
+}
+
+func TestParseSelect(t *testing.T) {
+	m, err := Parse(`{gender, select, male {He} female {She} other {They}} liked your post`)
+	assert.NoError(t, err)
+	assert.Equal(t, Message{
+		&Select{Name: "gender", Type: TypeSelect, Cases: []*Case{
+			{Key: "male", Message: Message{&Text{Value: "He"}}},
+			{Key: "female", Message: Message{&Text{Value: "She"}}},
+			{Key: "other", Message: Message{&Text{Value: "They"}}},
+		}},
+		&Text{Value: " liked your post"},
+	}, m)
+	assert.True(t, m.IsComplex())
+}
+
+func TestParseNestedPlural(t *testing.T) {
+	m, err := Parse(`{gender, select, female {{count, plural, offset:1 =0 {She has no friends} one {She has # friend} other {She has # friends, '#'1}}} other {{name} has {count, selectordinal, one {#st} other {#th}} place}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []Arg{{Name: "gender", Type: TypeSelect}, {Name: "count", Type: TypePlural}, {Name: "name"}}, m.Args())
+
+	plural := m[0].(*Select).Case("female").Message[0].(*Select)
+	assert.Equal(t, 1, plural.Offset)
+	assert.Equal(t, Message{&Text{Value: "She has "}, &Pound{}, &Text{Value: " friends, #1"}}, plural.Case("other").Message)
+	assert.Nil(t, plural.Case("few"))
+
+	// "#" is a literal outside of the plural cases
+	m, err = Parse(`#{n, select, other {#}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, Message{&Text{Value: "#"}, &Select{Name: "n", Type: TypeSelect, Cases: []*Case{{Key: "other", Message: Message{&Text{Value: "#"}}}}}}, m)
+}
+
+func TestMessageString(t *testing.T) {
+	sources := []string{
+		`Hello, {name}!`,
+		`{count, plural, offset:1 =0 {none} one {# item} other {# items, '#'}}`,
+		`{gender, select, male {He's {n, number}} other {They '{'}}`,
+	}
+	for _, src := range sources {
+		m, err := Parse(src)
+		if assert.NoError(t, err, src) {
+			assert.Equal(t, src, m.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		message string
+		err     string
+	}{
+		{`Hello, {name`, `unterminated argument at position 8`},
+		{`Hello, {}`, `argument name expected at position 9`},
+		{`Hello }`, `unexpected "}" at position 7`},
+		{`{n, plural, one {# item}}`, `"other" case is required in "n" argument at position 1`},
+		{`{n, plural, some {x} other {y}}`, `invalid plural case "some" at position 13`},
+		{`{n, select, =1 {x} other {y}}`, `invalid case "=1" at position 13`},
+		{`{n, select, a {x} a {y} other {z}}`, `duplicate case "a" at position 19`},
+		{`{n, select, a x}`, `"{" expected after case "a" at position 15`},
+		{`{n, select, other {x}`, `unterminated argument at position 1`},
+		{`{n, plural, other {'{x}}`, `unterminated quoted text at position 25`},
+		{`{n, number, {x}}`, `unexpected "{" in argument style at position 13`},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.message)
+		if assert.Error(t, err, test.message) {
+			assert.IsType(t, &SyntaxError{}, err)
+			assert.Equal(t, test.err, err.Error(), test.message)
+		}
+	}
+}
+
+func TestMessageStringApostrophes(t *testing.T) {
+	m := Message{&Text{Value: "It's '"}, &Placeholder{Name: "x"}, &Text{Value: "'{''}"}}
+	assert.Equal(t, `It's ''{x}'''{''''}'`, m.String())
+
+	parsed, err := Parse(m.String())
+	assert.NoError(t, err)
+	assert.Equal(t, m, parsed)
+}
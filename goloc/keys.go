@@ -0,0 +1,112 @@
+package goloc
+
+import (
+	"strings"
+
+	"github.com/s0nerik/goloc/utils"
+)
+
+const (
+	// KeyCaseNone leaves keys as they're specified in the sheet.
+	KeyCaseNone = `none`
+	// KeyCaseSnake converts keys into snake_case.
+	KeyCaseSnake = `snake_case`
+	// KeyCaseCamel converts keys into camelCase.
+	KeyCaseCamel = `camelCase`
+)
+
+// KeyValidator can be implemented by a platform to reject keys which would produce invalid resources
+// (e.g. Android resource names or Dart identifiers).
+type KeyValidator interface {
+	// Returns nil if key is valid and non-nil error otherwise.
+	ValidateKey(key Key) error
+}
+
+// NormalizeKey converts a key into a given case (one of KeyCaseNone, KeyCaseSnake or KeyCaseCamel).
+// Each part of the key separated by "." is converted separately, so that keys can still be split by prefix.
+func NormalizeKey(key Key, keyCase string) Key {
+	var convert func(string) string
+	switch keyCase {
+	case KeyCaseSnake:
+		convert = utils.SnakeCase
+	case KeyCaseCamel:
+		convert = utils.CamelCase
+	default:
+		return key
+	}
+
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = convert(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+const (
+	// OnDuplicateError stops the execution if a key is defined more than once.
+	OnDuplicateError = `error`
+	// OnDuplicateWarn reports duplicate keys as warnings and uses the first definition.
+	OnDuplicateWarn = `warn`
+	// OnDuplicateFirst silently uses the first definition of a duplicate key.
+	OnDuplicateFirst = `first`
+	// OnDuplicateLast silently uses the last definition of a duplicate key.
+	OnDuplicateLast = `last`
+)
+
+// keySet keeps track of the defined keys to detect duplicates.
+type keySet struct {
+	onDuplicate string
+	ignoreCase  bool
+	// keys maps the matched form of a key (see match) to the actual key.
+	keys map[Key]Key
+}
+
+func newKeySet(onDuplicate string, ignoreCase bool) *keySet {
+	return &keySet{onDuplicate: onDuplicate, ignoreCase: ignoreCase, keys: map[Key]Key{}}
+}
+
+func (s *keySet) match(key Key) Key {
+	if s.ignoreCase {
+		return strings.ToLower(key)
+	}
+	return key
+}
+
+// add registers a key defined at a given cell. If a matching key is already defined in meta, either an error is
+// returned or the duplicate is resolved according to the onDuplicate mode: keep is false if the new definition must be
+// ignored, replaced is the previously defined key to be removed otherwise.
+func (s *keySet) add(meta LocalizationMeta, key Key, cell Cell) (keep bool, replaced Key, warning error, err error) {
+	existing, ok := s.keys[s.match(key)]
+	if !ok {
+		s.keys[s.match(key)] = key
+		return true, "", nil, nil
+	}
+
+	keep, warning, err = s.resolve(&duplicateKeyError{cell: cell, firstCell: meta[existing].Cell, key: key})
+	if !keep {
+		return false, "", warning, err
+	}
+	s.keys[s.match(key)] = key
+	return true, existing, nil, nil
+}
+
+// defined returns the actual key matching a given one if it is already defined.
+func (s *keySet) defined(key Key) (Key, bool) {
+	existing, ok := s.keys[s.match(key)]
+	return existing, ok
+}
+
+// resolve resolves a duplicate definition according to the onDuplicate mode: keep is true if the new definition must
+// replace the previous one.
+func (s *keySet) resolve(duplicate *duplicateKeyError) (keep bool, warning error, err error) {
+	switch s.onDuplicate {
+	case OnDuplicateWarn:
+		return false, duplicate, nil
+	case OnDuplicateFirst:
+		return false, nil, nil
+	case OnDuplicateLast:
+		return true, nil, nil
+	default:
+		return false, nil, duplicate
+	}
+}
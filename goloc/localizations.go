@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/s0nerik/goloc/goloc/re"
@@ -15,7 +16,7 @@ type langColumns = map[int]Lang
 var DefaultEmptyLocRegexp, _ = regexp.Compile("^$")
 
 // ParseLocalizations parses formats given the raw table data and returns, if successful, mappings
-// for each localized string in different languages.
+// for each localized string in different languages. Keys defined more than once are reported as errors.
 func ParseLocalizations(
 	rawData [][]RawCell,
 	platform Platform,
@@ -25,21 +26,120 @@ func ParseLocalizations(
 	errorIfMissing bool,
 	emptyLocalizationRegexp *regexp.Regexp,
 ) (loc Localizations, formatArgs LocalizationFormatArgs, warnings []error, error error) {
+	loc, formatArgs, _, warnings, error = parseLocalizations(rawData, platform, formats, tabName, keyColumn, "", "", nil, KeyCaseNone, OnDuplicateError, false, errorIfMissing, emptyLocalizationRegexp)
+	return
+}
+
+// ParseLocalizationTabs parses localizations from multiple tabs and merges them into a single mapping.
+// Each key must be defined in only one of the tabs. Descriptions are read from the descriptionColumn if it's present.
+// Rows can be targeted at specific platforms via the platformsColumn (see RowTargetsPlatform), names which aren't
+// in knownPlatforms are reported as warnings. Keys are converted into keyCase (see NormalizeKey) and validated if the
+// platform is a KeyValidator. Keys defined more than once (ignoring the case if ignoreKeyCase is true) are handled
+// according to onDuplicate (one of OnDuplicateError, OnDuplicateWarn, OnDuplicateFirst or OnDuplicateLast).
+func ParseLocalizationTabs(
+	tabs []Tab,
+	platform Platform,
+	formats Formats,
+	keyColumn string,
+	descriptionColumn string,
+	platformsColumn string,
+	knownPlatforms []string,
+	keyCase string,
+	onDuplicate string,
+	ignoreKeyCase bool,
+	errorIfMissing bool,
+	emptyLocalizationRegexp *regexp.Regexp,
+) (loc Localizations, formatArgs LocalizationFormatArgs, meta LocalizationMeta, warnings []error, error error) {
+	loc = Localizations{}
 	formatArgs = LocalizationFormatArgs{}
+	meta = LocalizationMeta{}
+
+	keys := newKeySet(onDuplicate, ignoreKeyCase)
+	for _, tab := range tabs {
+		tabLoc, tabFormatArgs, tabMeta, tabWarnings, err := parseLocalizations(tab.Rows, platform, formats, tab.Name, keyColumn, descriptionColumn, platformsColumn, knownPlatforms, keyCase, onDuplicate, ignoreKeyCase, errorIfMissing, emptyLocalizationRegexp)
+		if err != nil {
+			error = err
+			return
+		}
+		warnings = append(warnings, tabWarnings...)
+
+		for _, key := range tabKeysInOrder(tabMeta) {
+			if m, ok := meta[key]; ok && m.SourceKey != tabMeta[key].SourceKey {
+				error = &keyCollisionError{cell: tabMeta[key].Cell, firstCell: m.Cell, key: key, sourceKey: tabMeta[key].SourceKey, firstSourceKey: m.SourceKey}
+				return
+			}
+			keep, replaced, warn, err := keys.add(meta, key, tabMeta[key].Cell)
+			if err != nil {
+				error = err
+				return
+			}
+			if warn != nil {
+				warnings = append(warnings, warn)
+			}
+			if !keep {
+				continue
+			}
+			if replaced != "" {
+				delete(loc, replaced)
+				delete(formatArgs, replaced)
+				delete(meta, replaced)
+			}
+			meta[key] = tabMeta[key]
+			loc[key] = tabLoc[key]
+			formatArgs[key] = tabFormatArgs[key]
+		}
+	}
+
+	return
+}
+
+// tabKeysInOrder returns keys in the order of their rows.
+func tabKeysInOrder(meta LocalizationMeta) []Key {
+	keys := make([]Key, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return meta[keys[i]].Cell.row < meta[keys[j]].Cell.row
+	})
+	return keys
+}
+
+func parseLocalizations(
+	rawData [][]RawCell,
+	platform Platform,
+	formats Formats,
+	tabName string,
+	keyColumn string,
+	descriptionColumn string,
+	platformsColumn string,
+	knownPlatforms []string,
+	keyCase string,
+	onDuplicate string,
+	ignoreKeyCase bool,
+	errorIfMissing bool,
+	emptyLocalizationRegexp *regexp.Regexp,
+) (loc Localizations, formatArgs LocalizationFormatArgs, meta LocalizationMeta, warnings []error, error error) {
+	formatArgs = LocalizationFormatArgs{}
+	meta = LocalizationMeta{}
 
 	if emptyLocalizationRegexp == nil {
 		emptyLocalizationRegexp = DefaultEmptyLocRegexp
 	}
 
-	keyColIndex, langCols, err := localizationColumnIndices(rawData, tabName, keyColumn)
+	cols, err := localizationColumnIndices(rawData, platform, tabName, keyColumn, descriptionColumn, platformsColumn, knownPlatforms)
 	if err != nil {
 		error = err
 		return
 	}
 
 	loc = Localizations{}
+	keys := newKeySet(onDuplicate, ignoreKeyCase)
+	arrays := map[Key]*arrayItems{}
+	var arrayKeys []Key
 	for index, row := range rawData[1:] {
 		actualRow := index + 2
+		keyColIndex := cols.rowKey(row)
 		if keyColIndex >= len(row) || len(strings.TrimSpace(row[keyColIndex])) == 0 {
 			if errorIfMissing {
 				error = newKeyMissingError(tabName, actualRow, keyColIndex)
@@ -48,33 +148,205 @@ func ParseLocalizations(
 			warnings = append(warnings, newKeyMissingError(tabName, actualRow, keyColIndex))
 			continue
 		}
-		key := strings.TrimSpace(row[keyColIndex])
-		if keyLoc, warn, err := keyLocalizations(platform, formats, tabName, actualRow, row, key, langCols, errorIfMissing, emptyLocalizationRegexp); err == nil {
+		if cols.platforms >= 0 && cols.platforms < len(row) {
+			targeted, unknown := RowTargetsPlatform(row[cols.platforms], platform, knownPlatforms)
+			for _, name := range unknown {
+				warnings = append(warnings, &unknownPlatformError{cell: *NewCell(tabName, uint(actualRow), uint(cols.platforms)), platformName: name})
+			}
+			if !targeted {
+				continue
+			}
+		}
+
+		sourceKey := strings.TrimSpace(row[keyColIndex])
+		keyCell := NewCell(tabName, uint(actualRow), uint(keyColIndex))
+		itemKey, arrayIndex := sourceKey, -1
+		if m := arrayKeyRegexp.FindStringSubmatch(sourceKey); m != nil {
+			if _, ok := platform.(ArrayWriter); ok {
+				sourceKey = m[1]
+				arrayIndex, _ = strconv.Atoi(m[2])
+			} else {
+				// The item is written as a plain "key[N]" string
+				warnings = append(warnings, &arrayNotSupportedError{cell: *keyCell, platformName: platform.Names()[0], key: sourceKey})
+			}
+		}
+		key := NormalizeKey(sourceKey, keyCase)
+		if m, ok := meta[key]; ok && m.SourceKey != sourceKey {
+			error = &keyCollisionError{cell: *keyCell, firstCell: m.Cell, key: key, sourceKey: sourceKey, firstSourceKey: m.SourceKey}
+			return
+		}
+		if existing, ok := keys.defined(key); ok && arrayIndex >= 0 && arrays[existing] != nil {
+			// Next item of an already defined array
+			warn, err := arrays[existing].add(keys, arrayIndex, itemKey, *keyCell, actualRow, row, cols.rowLangs(row, emptyLocalizationRegexp))
+			if err != nil {
+				error = err
+				return
+			}
+			if warn != nil {
+				warnings = append(warnings, warn)
+			}
+			if cols.description >= 0 && cols.description < len(row) && meta[existing].Description == "" {
+				meta[existing].Description = strings.TrimSpace(row[cols.description])
+			}
+			continue
+		}
+		if v, ok := platform.(KeyValidator); ok {
+			if err := v.ValidateKey(key); err != nil {
+				error = &invalidKeyError{cell: *keyCell, key: key, platformName: platform.Names()[0], reason: err}
+				return
+			}
+		}
+
+		keep, replaced, warn, err := keys.add(meta, key, *keyCell)
+		if err != nil {
+			error = err
+			return
+		}
+		if warn != nil {
+			warnings = append(warnings, warn)
+		}
+		if !keep {
+			continue
+		}
+		if replaced != "" {
+			delete(loc, replaced)
+			delete(formatArgs, replaced)
+			delete(meta, replaced)
+			delete(arrays, replaced)
+		}
+
+		if arrayIndex >= 0 {
+			arrays[key] = newArrayItems(key, *keyCell)
+			arrayKeys = append(arrayKeys, key)
+			// The first item of an array can't be a duplicate
+			arrays[key].add(keys, arrayIndex, itemKey, *keyCell, actualRow, row, cols.rowLangs(row, emptyLocalizationRegexp))
+			meta[key] = &KeyMeta{Cell: *keyCell, SourceKey: sourceKey}
+			if cols.description >= 0 && cols.description < len(row) {
+				meta[key].Description = strings.TrimSpace(row[cols.description])
+			}
+			continue
+		}
+
+		langCols := cols.rowLangs(row, emptyLocalizationRegexp)
+		messages, messageArgs, err := keyMessages(platform, tabName, actualRow, row, key, langCols, emptyLocalizationRegexp)
+		if err != nil {
+			error = err
+			return
+		}
+
+		var keyArgs []FormatKey
+		var argIndices map[string]int
+		if messages == nil {
+			keyArgs, argIndices, err = keyFormatArgs(platform, tabName, actualRow, row, key, langCols, emptyLocalizationRegexp)
+			if err != nil {
+				error = err
+				return
+			}
+		}
+
+		if keyLoc, warn, err := keyLocalizations(platform, formats, tabName, actualRow, row, key, langCols, argIndices, messages != nil, errorIfMissing, emptyLocalizationRegexp); err == nil {
 			if len(warn) > 0 {
 				warnings = append(warnings, warn...)
 			}
 			loc[key] = keyLoc
+			meta[key] = &KeyMeta{Cell: *keyCell, SourceKey: sourceKey, Messages: messages, MessageArgs: messageArgs}
+			if cols.description >= 0 && cols.description < len(row) {
+				meta[key].Description = strings.TrimSpace(row[cols.description])
+			}
 		} else {
 			error = err
 			return
 		}
 
-		formatArgs[key], error = keyFormatArgs(platform, tabName, actualRow, row, key, langCols, emptyLocalizationRegexp)
-		if error != nil {
+		formatArgs[key] = keyArgs
+	}
+
+	for _, key := range arrayKeys {
+		a, ok := arrays[key]
+		if !ok {
+			continue
+		}
+		keyLoc, items, warn, err := a.localizations(platform, errorIfMissing, emptyLocalizationRegexp)
+		if err != nil {
+			error = err
 			return
 		}
+		warnings = append(warnings, warn...)
+		loc[key] = keyLoc
+		meta[key].Items = items
+		formatArgs[key] = nil
 	}
 
 	return
 }
 
+// localizationColumns holds indices of the localizations tab columns. Indices of the missing optional columns are -1.
+type localizationColumns struct {
+	key         int
+	description int
+	platforms   int
+	langs       langColumns
+	// keyOverride is an index of the "<key column>_<platform>" column for the current platform.
+	keyOverride int
+	// langOverrides are indices of the "lang_<lang>_<platform>" columns for the current platform.
+	langOverrides map[Lang]int
+}
+
+// rowKey returns an index of the column containing the key of a given row, taking the key override into account.
+func (c *localizationColumns) rowKey(row []RawCell) int {
+	if c.keyOverride >= 0 && c.keyOverride < len(row) && strings.TrimSpace(row[c.keyOverride]) != "" {
+		return c.keyOverride
+	}
+	return c.key
+}
+
+// rowLangs returns language columns of a given row, replacing generic language columns with the platform-specific
+// ones which have a value in this row.
+func (c *localizationColumns) rowLangs(row []RawCell, emptyLocalizationRegexp *regexp.Regexp) langColumns {
+	if len(c.langOverrides) == 0 {
+		return c.langs
+	}
+
+	result := langColumns{}
+	for i, lang := range c.langs {
+		result[i] = lang
+	}
+	for lang, o := range c.langOverrides {
+		generic := -1
+		for i, l := range c.langs {
+			if l == lang {
+				generic = i
+			}
+		}
+		hasValue := o < len(row) && !emptyLocalizationRegexp.MatchString(strings.TrimSpace(row[o]))
+		if generic >= 0 && !hasValue {
+			continue
+		}
+		delete(result, generic)
+		result[o] = lang
+	}
+	return result
+}
+
+var langOverrideColumnRegexp = regexp.MustCompile(`^lang_([a-z]{2})_(.+)$`)
+
 func localizationColumnIndices(
 	rawData [][]RawCell,
+	platform Platform,
 	tabName string,
 	keyColumn string,
-) (keyColIndex int, langCols langColumns, err error) {
-	keyColIndex = -1
-	langCols = langColumns{}
+	descriptionColumn string,
+	platformsColumn string,
+	knownPlatforms []string,
+) (cols localizationColumns, err error) {
+	cols = localizationColumns{
+		key:           -1,
+		description:   -1,
+		platforms:     -1,
+		langs:         langColumns{},
+		keyOverride:   -1,
+		langOverrides: map[Lang]int{},
+	}
 
 	if len(rawData) == 0 {
 		err = &emptySheetError{tab: tabName}
@@ -87,22 +359,45 @@ func localizationColumnIndices(
 		return
 	}
 
+	isPlatformName := func(name string) bool {
+		return containsFold(knownPlatforms, name) || containsFold(platform.Names(), name)
+	}
+
 	for i, val := range firstRow {
 		if val == keyColumn {
-			keyColIndex = i
+			cols.key = i
+			continue
+		}
+		if descriptionColumn != "" && val == descriptionColumn {
+			cols.description = i
+		}
+		if platformsColumn != "" && val == platformsColumn {
+			cols.platforms = i
+		}
+		if name := strings.TrimPrefix(val, keyColumn+"_"); name != val && isPlatformName(name) {
+			if containsFold(platform.Names(), name) {
+				cols.keyOverride = i
+			}
+			continue
+		}
+		if m := langOverrideColumnRegexp.FindStringSubmatch(val); m != nil && isPlatformName(m[2]) {
+			if containsFold(platform.Names(), m[2]) {
+				cols.langOverrides[m[1]] = i
+			}
+			continue
 		}
 		lang := re.LangColumnNameRegexp().FindStringSubmatch(val)
 		if lang != nil {
-			langCols[i] = lang[1]
+			cols.langs[i] = lang[1]
 		}
 	}
 
-	if keyColIndex == -1 {
-		err = &columnNotFoundError{Cell{tabName, uint(1), uint(keyColIndex)}, keyColumn}
+	if cols.key == -1 {
+		err = &columnNotFoundError{Cell{tabName, uint(1), uint(cols.key)}, keyColumn}
 		return
 	}
 
-	if len(langCols) == 0 {
+	if len(cols.langs) == 0 && len(cols.langOverrides) == 0 {
 		err = &langColumnsNotFoundError{Cell{tabName, uint(1), uint(0)}}
 		return
 	}
@@ -110,6 +405,49 @@ func localizationColumnIndices(
 	return
 }
 
+// containsFold reports whether names contain a given name, ignoring the case.
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RowTargetsPlatform reports whether a row with a given platforms cell value is targeted at the platform.
+// The value is a comma-separated list of platform names (e.g. "android,ios"). Names prefixed with "!" exclude
+// the platform (e.g. "!web"), a list of exclusions only targets all other platforms. An empty value targets all
+// platforms. Names are case-insensitive, names not found in knownPlatforms are returned as unknown.
+func RowTargetsPlatform(value string, platform Platform, knownPlatforms []string) (targeted bool, unknown []string) {
+	hasInclusions := false
+	included := false
+	excluded := false
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		exclusion := strings.HasPrefix(name, "!")
+		name = strings.TrimSpace(strings.TrimPrefix(name, "!"))
+		if name == "" {
+			continue
+		}
+		if !containsFold(knownPlatforms, name) && !containsFold(platform.Names(), name) {
+			unknown = append(unknown, name)
+		}
+		if exclusion {
+			excluded = excluded || containsFold(platform.Names(), name)
+		} else {
+			hasInclusions = true
+			included = included || containsFold(platform.Names(), name)
+		}
+	}
+
+	targeted = !excluded && (included || !hasInclusions)
+	return
+}
+
+// keyFormatArgs checks that each language uses the same set of placeholder arguments (in any order) and returns the
+// format names of the arguments in their positional order along with the index of each argument (see placeholders).
+// Arguments are ordered by their explicit positions, if specified, or by their first occurrence in the first language.
 func keyFormatArgs(
 	platform Platform,
 	tab string,
@@ -118,37 +456,99 @@ func keyFormatArgs(
 	key Key,
 	langColumns langColumns,
 	emptyLocalizationRegexp *regexp.Regexp,
-) (formatArgs []string, err error) {
-	langFormatArgs := map[int][]string{}
-
+) (formatArgs []FormatKey, argIndices map[string]int, err error) {
+	var columns []int
 	for i := range langColumns {
-		if i < len(row) {
-			val := strings.TrimSpace(row[i])
-			valWithoutSpecChars := withReplacedSpecialChars(platform, val)
-			if !emptyLocalizationRegexp.MatchString(valWithoutSpecChars) {
-				langFormatArgs[i] = FormatArgs(valWithoutSpecChars)
-			}
-		}
+		columns = append(columns, i)
 	}
+	sort.Ints(columns)
+
+	var order []string
+	var argFormats map[string]FormatKey
+	names := map[string]string{}
+	positions := map[string]int{}
+	positionCells := map[string]Cell{}
+	first := true
+	for _, col := range columns {
+		if col >= len(row) {
+			continue
+		}
+		valWithoutSpecChars := WithReplacedSpecialChars(platform, strings.TrimSpace(row[col]))
+		if emptyLocalizationRegexp.MatchString(valWithoutSpecChars) {
+			continue
+		}
 
-	if len(langFormatArgs) > 1 {
-		first := true
-		var prevArgs []string
-		for col, fArgs := range langFormatArgs {
-			if !first && !reflect.DeepEqual(fArgs, prevArgs) {
+		cell := *NewCell(tab, uint(line), uint(col))
+		langFormats := map[string]FormatKey{}
+		ph, ids := placeholders(valWithoutSpecChars)
+		for i, p := range ph {
+			id := ids[i]
+			if f, ok := langFormats[id]; ok && f != p.format {
 				err = newFormatArgsDifferentError(tab, line, col, key, langColumns[col])
 				return
 			}
-			prevArgs = fArgs
+			if _, ok := langFormats[id]; !ok && first {
+				order = append(order, id)
+			}
+			langFormats[id] = p.format
+			names[id] = p.arg()
+
+			if p.position > 0 {
+				if pos, ok := positions[id]; ok && pos != p.position {
+					err = &formatArgPositionError{cell: cell, arg: p.arg(), position: p.position, reason: fmt.Sprintf("position %v is specified at %v", pos, positionCells[id])}
+					return
+				}
+				positions[id] = p.position
+				positionCells[id] = cell
+			}
+		}
+
+		if first {
+			argFormats = langFormats
 			first = false
+		} else if !reflect.DeepEqual(langFormats, argFormats) {
+			err = newFormatArgsDifferentError(tab, line, col, key, langColumns[col])
+			return
 		}
 	}
 
-	if len(langFormatArgs) > 0 {
-		for _, v := range langFormatArgs {
-			formatArgs = v
-			break
+	if len(order) == 0 {
+		return
+	}
+
+	// Place the arguments with explicit positions first, then fill the remaining positions in the order of occurrence
+	slots := make([]string, len(order))
+	for _, id := range order {
+		pos, ok := positions[id]
+		if !ok {
+			continue
+		}
+		if pos > len(order) {
+			err = &formatArgPositionError{cell: positionCells[id], arg: names[id], position: pos, reason: fmt.Sprintf("there are only %v arguments", len(order))}
+			return
+		}
+		if slots[pos-1] != "" {
+			err = &formatArgPositionError{cell: positionCells[id], arg: names[id], position: pos, reason: fmt.Sprintf(`position is already taken by "%v"`, names[slots[pos-1]])}
+			return
 		}
+		slots[pos-1] = id
+	}
+	next := 0
+	for _, id := range order {
+		if _, ok := positions[id]; ok {
+			continue
+		}
+		for slots[next] != "" {
+			next++
+		}
+		slots[next] = id
+	}
+
+	formatArgs = make([]FormatKey, len(slots))
+	argIndices = map[string]int{}
+	for i, id := range slots {
+		formatArgs[i] = argFormats[id]
+		argIndices[id] = i
 	}
 
 	return
@@ -162,6 +562,8 @@ func keyLocalizations(
 	row []string,
 	key Key,
 	langColumns langColumns,
+	argIndices map[string]int,
+	isMessage bool,
 	errorIfMissing bool,
 	emptyLocalizationRegexp *regexp.Regexp,
 ) (keyLoc map[Lang]string, warnings []error, error error) {
@@ -171,8 +573,13 @@ func keyLocalizations(
 		if i < len(row) {
 			val := strings.TrimSpace(row[i])
 			if match := emptyLocalizationRegexp.MatchString(val); !match {
-				valWithoutSpecChars := withReplacedSpecialChars(platform, val)
-				finalValue, err := withReplacedFormats(platform, valWithoutSpecChars, formats, tab, line, i)
+				valWithoutSpecChars := WithReplacedSpecialChars(platform, val)
+				if isMessage {
+					// ICU messages are written as is
+					keyLoc[lang] = valWithoutSpecChars
+					continue
+				}
+				finalValue, err := withReplacedFormats(platform, valWithoutSpecChars, formats, argIndices, tab, line, i)
 				if err != nil {
 					error = err
 					return
@@ -194,35 +601,71 @@ func keyLocalizations(
 	return
 }
 
-func withReplacedFormats(platform Platform, str string, formats Formats, tab string, row int, column int) (string, error) {
-	var index int
+// withReplacedFormats replaces placeholders with the platform format strings. Index of each format string is the
+// index of its argument from argIndices.
+func withReplacedFormats(platform Platform, str string, formats Formats, argIndices map[string]int, tab string, row int, column int) (string, error) {
+	var occurrence int
 	var err error
-	formatStringArgs := &FormatStringArgs{}
+
+	ph, ids := placeholders(str)
+	reordered := false
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			reordered = reordered || argIndices[id] != len(seen)
+			seen[id] = true
+		}
+	}
+
+	// Formats of the unnamed arguments by their indices
+	unnamed := map[int]FormatKey{}
+	for i, p := range ph {
+		if p.name == "" {
+			unnamed[argIndices[ids[i]]] = p.format
+		}
+	}
+
 	strWithReplacedFormats := re.FormatRegexp().ReplaceAllStringFunc(str, func(formatName string) string {
-		defer func() { index++ }()
-		if len(formatName) < 2 {
+		defer func() { occurrence++ }()
+		if len(formatName) < 2 || occurrence >= len(ph) {
 			err = fmt.Errorf(`%v: something went wrong, please submit an issue with the values in the problematic row`, Cell{tab, uint(row), uint(column)})
 			return ""
 		}
 
-		name := formatName[1 : len(formatName)-1]
+		p := ph[occurrence]
 		// Check if format specification exist and report an error if not
-		if _, ok := formats[name]; !ok {
+		if _, ok := formats[p.format]; !ok {
 			if err == nil {
-				err = &formatNotFoundError{Cell{tab, uint(row), uint(column)}, name}
+				err = &formatNotFoundError{Cell{tab, uint(row), uint(column)}, p.format}
 			}
 			return ""
 		}
 
-		formatStringArgs.Index = index
-		formatStringArgs.Format = formats[name]
-		return platform.FormatString(formatStringArgs)
+		index := argIndices[ids[occurrence]]
+		unnamedOccurrence := 0
+		if p.name == "" {
+			for i, format := range unnamed {
+				if i <= index && format == p.format {
+					unnamedOccurrence++
+				}
+			}
+		}
+
+		return platform.FormatString(&FormatStringArgs{
+			Index:      index,
+			Name:       p.format,
+			Format:     formats[p.format],
+			ArgName:    p.name,
+			Occurrence: unnamedOccurrence,
+			Reordered:  reordered,
+		})
 	})
 
 	return strWithReplacedFormats, err
 }
 
-func withReplacedSpecialChars(platform Platform, str string) string {
+// WithReplacedSpecialChars guards the special characters of a string using the platform ReplacementChars.
+func WithReplacedSpecialChars(platform Platform, str string) string {
 	specChars := platform.ReplacementChars()
 
 	replacements := make([]string, 0, len(specChars))
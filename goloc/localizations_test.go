@@ -1,9 +1,13 @@
 package goloc
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/s0nerik/goloc/goloc/icu"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -158,6 +162,249 @@ func TestLocalizationsMissingFormat(t *testing.T) {
 	}
 }
 
+type positionalMockPlatform struct {
+	*mockPlatform
+}
+
+func (positionalMockPlatform) FormatString(args *FormatStringArgs) string {
+	return fmt.Sprintf("%%%v$%v", args.Index+1, strings.TrimPrefix(args.Format, "%"))
+}
+
+func TestLocalizationFormatArgsOrder(t *testing.T) {
+	data := [][]RawCell{
+		{"key", "lang_en", "lang_de", "lang_fr"},
+		{"unnamed", "{x} of {y}", "{y} von {x}", ""},
+		{"named", "{who:x} has {count:z} items", "{count:z} Sachen hat {who:x}", "{who:x} {who:x} {count:z}"},
+		{"positions", "{2$x} then {1$y}", "{y}, {x}", ""},
+	}
+
+	platform := positionalMockPlatform{newMockPlatform(nil)}
+	loc, fArgs, _, err := ParseLocalizations(data, platform, formats(), "", "key", false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Localizations{
+		"unnamed":   {"en": "%1$s of %2$s", "de": "%2$s von %1$s", "fr": ""},
+		"named":     {"en": "%1$s has %2$s items", "de": "%2$s Sachen hat %1$s", "fr": "%1$s %1$s %2$s"},
+		"positions": {"en": "%2$s then %1$s", "de": "%1$s, %2$s", "fr": ""},
+	}, loc)
+	assert.Equal(t, LocalizationFormatArgs{
+		"unnamed":   {"x", "y"},
+		"named":     {"x", "z"},
+		"positions": {"y", "x"},
+	}, fArgs)
+}
+
+func TestLocalizationFormatArgsErrors(t *testing.T) {
+	tests := []struct {
+		value string
+		err   string
+	}{
+		{"{x} {x}", `main!C2: format arguments must be the same for each language`},
+		{"{who:y}", `main!C2: format arguments must be the same for each language`},
+		{"{3$who:x}", `main!C2: invalid position 3 of "who" argument (there are only 1 arguments)`},
+		{"{2$who:x}, {1$who:x}", `main!C2: invalid position 1 of "who" argument (position 2 is specified at main!C2)`},
+	}
+
+	for _, test := range tests {
+		data := [][]RawCell{
+			{"key", "lang_en", "lang_de"},
+			{"k", "{who:x}", test.value},
+		}
+		_, _, _, err := ParseLocalizations(data, newMockPlatform(nil), formats(), "main", "key", false, nil)
+		if assert.Error(t, err, test.value) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+
+	data := [][]RawCell{
+		{"key", "lang_en"},
+		{"k", "{1$x} {1$y}"},
+	}
+	_, _, _, err := ParseLocalizations(data, newMockPlatform(nil), formats(), "main", "key", false, nil)
+	if assert.IsType(t, &formatArgPositionError{}, err) {
+		assert.Equal(t, `main!B2: invalid position 1 of "{y}" argument (position is already taken by "{x}")`, err.Error())
+	}
+}
+
+func TestLocalizationInlinePlaceholders(t *testing.T) {
+	data := [][]RawCell{
+		{"key", "lang_en", "lang_de"},
+		{"cart", "{name, string} has {count,number} items for {price, number, currency}", "{2$count, number} Sachen für {price, number, currency}, {name, string}"},
+		{"bad", "{count, number, unknown}", ""},
+	}
+
+	platform := positionalMockPlatform{newMockPlatform(nil)}
+	formats := Formats{InlineFormatString: "s", InlineFormatNumber: "d", InlineFormatCurrency: ".2f"}
+
+	_, _, _, err := ParseLocalizations(data, platform, formats, "main", "key", false, nil)
+	if assert.IsType(t, &formatNotFoundError{}, err) {
+		assert.Equal(t, `main!B3: no such format - "number, unknown"`, err.Error())
+	}
+
+	loc, fArgs, _, err := ParseLocalizations(data[:2], platform, formats, "main", "key", false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Localizations{
+		"cart": {"en": "%1$s has %2$d items for %3$.2f", "de": "%2$d Sachen für %3$.2f, %1$s"},
+	}, loc)
+	assert.Equal(t, LocalizationFormatArgs{
+		"cart": {InlineFormatString, InlineFormatNumber, InlineFormatCurrency},
+	}, fArgs)
+}
+
+type messageWriterMockPlatform struct {
+	*mockPlatform
+}
+
+func (messageWriterMockPlatform) ValidateMessage(message icu.Message) error {
+	for _, arg := range message.Args() {
+		if arg.Type == icu.TypeSelectOrdinal {
+			return errors.New("selectordinal isn't supported")
+		}
+	}
+	return nil
+}
+
+func TestLocalizationMessages(t *testing.T) {
+	data := [][]RawCell{
+		{"key", "lang_en", "lang_de"},
+		{"liked", "{gender, select, female {She} other {They}} liked {count, plural, one {# ~post} other {# posts}}", "{count, plural, one {# Beitrag} other {# Beiträge}} von {gender, select, other {ihnen}}"},
+		{"plain", "{x} ~", "{x}"},
+	}
+
+	platform := messageWriterMockPlatform{newMockPlatform(nil)}
+	loc, fArgs, meta, _, err := ParseLocalizationTabs([]Tab{{Name: "main", Rows: data}}, platform, formats(), "key", "", "", nil, KeyCaseNone, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "{gender, select, female {She} other {They}} liked {count, plural, one {# tildepost} other {# posts}}", loc["liked"]["en"])
+	assert.Nil(t, fArgs["liked"])
+	assert.Equal(t, []FormatKey{"x"}, fArgs["plain"])
+	assert.Equal(t, []icu.Arg{{Name: "gender", Type: icu.TypeSelect}, {Name: "count", Type: icu.TypePlural}}, meta["liked"].MessageArgs)
+	assert.Equal(t, `{count, plural, one {# Beitrag} other {# Beiträge}} von {gender, select, other {ihnen}}`, meta["liked"].Messages["de"].String())
+	assert.Nil(t, meta["plain"].Messages)
+}
+
+func TestLocalizationMessagesErrors(t *testing.T) {
+	header := []RawCell{"key", "lang_en", "lang_de"}
+	for _, tc := range []struct {
+		row      []RawCell
+		platform Platform
+		expected string
+	}{
+		{[]RawCell{"syntax", "{gender, select, female {She}}", ""}, messageWriterMockPlatform{newMockPlatform(nil)}, `main!B2: invalid ICU message ("other" case is required in "gender" argument at position 1)`},
+		{[]RawCell{"ordinal", "{n, selectordinal, one {#st} other {#th}}", ""}, messageWriterMockPlatform{newMockPlatform(nil)}, `main!B2: ICU message can't be written for platform "mock" (selectordinal isn't supported)`},
+		{[]RawCell{"args", "{gender, select, other {They}}", "{count, plural, other {Sie}}"}, messageWriterMockPlatform{newMockPlatform(nil)}, `main!C2: format arguments must be the same for each language`},
+		{[]RawCell{"unsupported", "{gender, select, other {They}}", ""}, newMockPlatform(nil), `main!B2: ICU messages with select, plural or selectordinal arguments aren't supported by "mock" platform`},
+	} {
+		_, _, _, err := ParseLocalizations([][]RawCell{header, tc.row}, tc.platform, formats(), "main", "key", false, nil)
+		if assert.Error(t, err) {
+			assert.Equal(t, tc.expected, err.Error())
+		}
+	}
+}
+
+type arrayWriterMockPlatform struct {
+	*mockPlatform
+}
+
+func (arrayWriterMockPlatform) LocalizedArray(args *LocalizedStringArgs) string {
+	return strings.Join(args.Items, ",")
+}
+
+func TestLocalizationArrays(t *testing.T) {
+	data := [][]RawCell{
+		{"key", "description", "lang_en", "lang_de"},
+		{"colors[1]", "", "Green", "Grün"},
+		{"title", "", "Title", "Titel"},
+		{"colors [0]", "Palette", "Red~", "Rot"},
+		{"pages[0]", "", "First", ""},
+	}
+
+	loc, fArgs, meta, warn, err := ParseLocalizationTabs([]Tab{{Name: "main", Rows: data}}, arrayWriterMockPlatform{newMockPlatform(nil)}, formats(), "key", "description", "", nil, KeyCaseNone, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Localizations{
+		"colors": {"en": "Redtilde\nGreen", "de": "Rot\nGrün"},
+		"title":  {"en": "Title", "de": "Titel"},
+		"pages":  {"en": "First", "de": ""},
+	}, loc)
+	assert.Equal(t, map[Lang][]string{"en": {"Redtilde", "Green"}, "de": {"Rot", "Grün"}}, meta["colors"].Items)
+	assert.Equal(t, "Palette", meta["colors"].Description)
+	assert.Equal(t, "main!A2", meta["colors"].Cell.String())
+	assert.Nil(t, meta["title"].Items)
+	assert.Nil(t, fArgs["colors"])
+	if assert.Len(t, warn, 1) {
+		assert.Equal(t, `main!D5: "pages" is missing for "de" language`, warn[0].Error())
+	}
+}
+
+func TestLocalizationArraysErrors(t *testing.T) {
+	header := []RawCell{"key", "lang_en", "lang_de"}
+	for _, tc := range []struct {
+		rows     [][]RawCell
+		platform Platform
+		expected string
+	}{
+		{[][]RawCell{{"a[0]", "x", "y"}, {"a[2]", "x", "y"}}, arrayWriterMockPlatform{newMockPlatform(nil)}, `main!A2: "a" array has no item with index 1`},
+		{[][]RawCell{{"a[0]", "x", "y"}, {"a[0]", "x", "y"}}, arrayWriterMockPlatform{newMockPlatform(nil)}, `main!A3: "a[0]" key is already defined at main!A2`},
+		{[][]RawCell{{"a[0]", "x", "y"}, {"a[1]", "x", ""}}, arrayWriterMockPlatform{newMockPlatform(nil)}, `main!C3: "a" array has 1 of 2 items for "de" language, each language must have the same number of items`},
+		{[][]RawCell{{"a", "x", "y"}, {"a[0]", "x", "y"}}, arrayWriterMockPlatform{newMockPlatform(nil)}, `main!A3: "a" key is already defined at main!A2`},
+	} {
+		_, _, _, err := ParseLocalizations(append([][]RawCell{header}, tc.rows...), tc.platform, formats(), "main", "key", false, nil)
+		if assert.Error(t, err) {
+			assert.Equal(t, tc.expected, err.Error())
+		}
+	}
+}
+
+func TestLocalizationArraysNotSupported(t *testing.T) {
+	data := [][]RawCell{
+		{"key", "lang_en", "lang_de"},
+		{"colors[0]", "Red", "Rot"},
+		{"colors[1]", "Green", "Grün"},
+	}
+
+	loc, _, _, warn, err := ParseLocalizationTabs([]Tab{{Name: "main", Rows: data}}, newMockPlatform(nil), formats(), "key", "", "", nil, KeyCaseNone, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Localizations{
+		"colors[0]": {"en": "Red", "de": "Rot"},
+		"colors[1]": {"en": "Green", "de": "Grün"},
+	}, loc)
+	if assert.Len(t, warn, 2) {
+		assert.Equal(t, `main!A2: string arrays aren't supported by "mock" platform, "colors[0]" is written as a plain key`, warn[0].Error())
+		assert.Equal(t, `main!A3: string arrays aren't supported by "mock" platform, "colors[1]" is written as a plain key`, warn[1].Error())
+	}
+}
+
+func TestLocalizationArraysDuplicateIndex(t *testing.T) {
+	data := [][]RawCell{
+		{"key", "lang_en"},
+		{"colors[0]", "Red"},
+		{"colors[1]", "Green"},
+		{"Colors[0]", "Blue"},
+	}
+	parse := func(onDuplicate string, ignoreCase bool) (Localizations, []error, error) {
+		loc, _, _, warn, err := ParseLocalizationTabs([]Tab{{Name: "main", Rows: data}}, arrayWriterMockPlatform{newMockPlatform(nil)}, formats(), "key", "", "", nil, KeyCaseNone, onDuplicate, ignoreCase, false, nil)
+		return loc, warn, err
+	}
+
+	_, _, err := parse(OnDuplicateError, true)
+	assert.EqualError(t, err, `main!A4: "Colors[0]" key is already defined at main!A2`)
+
+	loc, warn, err := parse(OnDuplicateWarn, true)
+	assert.Nil(t, err)
+	assert.Equal(t, Localizations{"colors": {"en": "Red\nGreen"}}, loc)
+	if assert.Len(t, warn, 1) {
+		assert.Equal(t, `main!A4: "Colors[0]" key is already defined at main!A2`, warn[0].Error())
+	}
+
+	loc, warn, err = parse(OnDuplicateFirst, true)
+	assert.Nil(t, err)
+	assert.Empty(t, warn)
+	assert.Equal(t, Localizations{"colors": {"en": "Red\nGreen"}}, loc)
+
+	loc, warn, err = parse(OnDuplicateLast, true)
+	assert.Nil(t, err)
+	assert.Empty(t, warn)
+	assert.Equal(t, Localizations{"colors": {"en": "Blue\nGreen"}}, loc)
+}
+
 func TestLocalizationsMissingLocalization(t *testing.T) {
 	dataBad := [][][]RawCell{
 		{
@@ -249,3 +496,258 @@ func TestLocalizationsMissingRegexpLocalization(t *testing.T) {
 		assert.Empty(t, warn)
 	}
 }
+
+func TestLocalizationTabs(t *testing.T) {
+	tabs := []Tab{
+		{
+			Name: "onboarding",
+			Rows: [][]RawCell{
+				{"key", "lang_en"},
+				{"welcome", "Welcome {x}"},
+			},
+		},
+		{
+			Name: "checkout",
+			Rows: [][]RawCell{
+				{"lang_en", "key"},
+				{"Pay", "pay"},
+				{"", "cancel"},
+			},
+		},
+	}
+
+	loc, fArgs, meta, warn, err := ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "description", "platforms", nil, KeyCaseNone, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []Key{"cancel", "pay", "welcome"}, loc.SortedKeys())
+	assert.Equal(t, []FormatKey{"x"}, fArgs["welcome"])
+	assert.Equal(t, "checkout!B2", meta["pay"].Cell.String())
+	if assert.Len(t, warn, 1) {
+		assert.IsType(t, &localizationMissingError{}, warn[0])
+		assert.Equal(t, "checkout!A3", warn[0].(*localizationMissingError).cell.String())
+	}
+}
+
+func TestLocalizationDescriptions(t *testing.T) {
+	tabs := []Tab{
+		{
+			Name: "main",
+			Rows: [][]RawCell{
+				{"key", "description", "lang_en"},
+				{"title", " Main screen title ", "Title"},
+				{"ok", "", "OK"},
+				{"cancel"},
+			},
+		},
+	}
+
+	_, _, meta, _, err := ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "description", "platforms", nil, KeyCaseNone, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "Main screen title", meta["title"].Description)
+	assert.Equal(t, "", meta["ok"].Description)
+	assert.Equal(t, "", meta["cancel"].Description)
+
+	_, _, meta, _, err = ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "", "platforms", nil, KeyCaseNone, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", meta["title"].Description)
+}
+
+func TestLocalizationPlatforms(t *testing.T) {
+	tabs := []Tab{
+		{
+			Name: "main",
+			Rows: [][]RawCell{
+				{"key", "platforms", "lang_en"},
+				{"everywhere", "", "A"},
+				{"mobile", "ios, Mock", "B"},
+				{"ios_only", "ios", "C"},
+				{"not_mock", "!mock", "D"},
+				{"not_web", "!web", "E"},
+				{"typo", "andorid,mock", "F"},
+			},
+		},
+	}
+
+	loc, _, _, warn, err := ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "description", "platforms", []string{"ios", "web", "mock"}, KeyCaseNone, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []Key{"everywhere", "mobile", "not_web", "typo"}, loc.SortedKeys())
+	if assert.Len(t, warn, 1) {
+		assert.IsType(t, &unknownPlatformError{}, warn[0])
+		assert.Equal(t, `main!B7: unknown platform "andorid"`, warn[0].Error())
+	}
+}
+
+func TestLocalizationPlatformOverrides(t *testing.T) {
+	tabs := []Tab{
+		{
+			Name: "main",
+			Rows: [][]RawCell{
+				{"key", "key_mock", "key_ios", "lang_en", "lang_en_mock", "lang_en_ios", "lang_zh_TW"},
+				{"title", "", "", "Title", "", "iOS title", "T"},
+				{"ok", "ok_button", "", "OK", "Okay", "", "O"},
+				{"only_ios", "", "ios_key", "A", "", "B", "C"},
+			},
+		},
+	}
+
+	loc, _, meta, warn, err := ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "description", "platforms", []string{"ios", "mock"}, KeyCaseNone, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, warn)
+	assert.Equal(t, Localizations{
+		"title":     {"en": "Title", "zh": "T"},
+		"ok_button": {"en": "Okay", "zh": "O"},
+		"only_ios":  {"en": "A", "zh": "C"},
+	}, loc)
+	assert.Equal(t, "main!B3", meta["ok_button"].Cell.String())
+	assert.Equal(t, "main!A2", meta["title"].Cell.String())
+}
+
+type mockKeyValidatorPlatform struct {
+	*mockPlatform
+}
+
+func (mockKeyValidatorPlatform) ValidateKey(key Key) error {
+	if strings.ContainsAny(key, " -") {
+		return errors.New("spaces and dashes are not allowed")
+	}
+	return nil
+}
+
+func TestLocalizationInvalidKey(t *testing.T) {
+	tabs := []Tab{
+		{
+			Name: "main",
+			Rows: [][]RawCell{
+				{"key", "lang_en"},
+				{"title", "Title"},
+				{"my key-1", "Key"},
+			},
+		},
+	}
+
+	platform := mockKeyValidatorPlatform{newMockPlatform(nil)}
+
+	_, _, _, _, err := ParseLocalizationTabs(tabs, platform, formats(), "key", "description", "platforms", nil, KeyCaseNone, OnDuplicateError, false, false, nil)
+	if assert.Error(t, err) {
+		assert.IsType(t, &invalidKeyError{}, err)
+		assert.Equal(t, `main!A3: key "my key-1" is invalid for platform "mock" (spaces and dashes are not allowed)`, err.Error())
+	}
+
+	loc, _, meta, _, err := ParseLocalizationTabs(tabs, platform, formats(), "key", "description", "platforms", nil, KeyCaseSnake, OnDuplicateError, false, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []Key{"my_key_1", "title"}, loc.SortedKeys())
+	assert.Equal(t, "my key-1", meta["my_key_1"].SourceKey)
+}
+
+func TestLocalizationKeyCollision(t *testing.T) {
+	tabs := []Tab{
+		{
+			Name: "main",
+			Rows: [][]RawCell{
+				{"key", "lang_en"},
+				{"screen_title", "Title"},
+				{"screenTitle", "Title"},
+			},
+		},
+	}
+
+	_, _, _, _, err := ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "description", "platforms", nil, KeyCaseCamel, OnDuplicateError, false, false, nil)
+	if assert.Error(t, err) {
+		assert.IsType(t, &keyCollisionError{}, err)
+		assert.Equal(t, `main!A3: "screenTitle" key becomes "screenTitle" after the normalization, which collides with "screen_title" defined at main!A2`, err.Error())
+	}
+
+	tabs = append(tabs, Tab{
+		Name: "other",
+		Rows: [][]RawCell{
+			{"key", "lang_en"},
+			{"Screen title", "Title"},
+		},
+	})
+	tabs[0].Rows = tabs[0].Rows[:2]
+
+	_, _, _, _, err = ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "description", "platforms", nil, KeyCaseSnake, OnDuplicateError, false, false, nil)
+	if assert.Error(t, err) {
+		assert.IsType(t, &keyCollisionError{}, err)
+		assert.Equal(t, "other!A2", err.(*keyCollisionError).cell.String())
+	}
+}
+
+func TestLocalizationDuplicateKeys(t *testing.T) {
+	tabs := []Tab{
+		{
+			Name: "main",
+			Rows: [][]RawCell{
+				{"key", "lang_en"},
+				{"title", "First"},
+				{"ok", "OK"},
+				{" title ", "Second"},
+			},
+		},
+		{
+			Name: "other",
+			Rows: [][]RawCell{
+				{"key", "lang_en"},
+				{"Title", "Third"},
+			},
+		},
+	}
+
+	parse := func(onDuplicate string, ignoreKeyCase bool) (Localizations, []error, error) {
+		loc, _, _, warn, err := ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "description", "platforms", nil, KeyCaseNone, onDuplicate, ignoreKeyCase, false, nil)
+		return loc, warn, err
+	}
+
+	_, _, err := parse(OnDuplicateError, false)
+	if assert.Error(t, err) {
+		assert.IsType(t, &duplicateKeyError{}, err)
+		assert.Equal(t, `main!A4: "title" key is already defined at main!A2`, err.Error())
+	}
+
+	loc, warn, err := parse(OnDuplicateWarn, true)
+	assert.Nil(t, err)
+	assert.Equal(t, Localizations{"title": {"en": "First"}, "ok": {"en": "OK"}}, loc)
+	if assert.Len(t, warn, 2) {
+		assert.Equal(t, `main!A4: "title" key is already defined at main!A2`, warn[0].Error())
+		assert.Equal(t, `other!A2: "Title" key is already defined at main!A2`, warn[1].Error())
+	}
+
+	loc, warn, err = parse(OnDuplicateFirst, false)
+	assert.Nil(t, err)
+	assert.Empty(t, warn)
+	assert.Equal(t, Localizations{"title": {"en": "First"}, "ok": {"en": "OK"}, "Title": {"en": "Third"}}, loc)
+
+	loc, _, err = parse(OnDuplicateLast, true)
+	assert.Nil(t, err)
+	assert.Equal(t, Localizations{"Title": {"en": "Third"}, "ok": {"en": "OK"}}, loc)
+
+	loc, _, err = parse(OnDuplicateLast, false)
+	assert.Nil(t, err)
+	assert.Equal(t, Localizations{"title": {"en": "Second"}, "ok": {"en": "OK"}, "Title": {"en": "Third"}}, loc)
+}
+
+func TestLocalizationTabsDuplicateKey(t *testing.T) {
+	tabs := []Tab{
+		{
+			Name: "onboarding",
+			Rows: [][]RawCell{
+				{"key", "lang_en"},
+				{"title", "Welcome"},
+			},
+		},
+		{
+			Name: "checkout",
+			Rows: [][]RawCell{
+				{"key", "lang_en"},
+				{"pay", "Pay"},
+				{"title", "Checkout"},
+			},
+		},
+	}
+
+	_, _, _, _, err := ParseLocalizationTabs(tabs, newMockPlatform(nil), formats(), "key", "description", "platforms", nil, KeyCaseNone, OnDuplicateError, false, false, nil)
+	if assert.Error(t, err) {
+		assert.IsType(t, &duplicateKeyError{}, err)
+		assert.Equal(t, "checkout!A3", err.(*duplicateKeyError).cell.String())
+		assert.Equal(t, "onboarding!A2", err.(*duplicateKeyError).firstCell.String())
+	}
+}
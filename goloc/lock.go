@@ -0,0 +1,157 @@
+package goloc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// TabHash is a content hash of a single localizations tab.
+type TabHash struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// Lock records the source data which was used to generate the localization files.
+type Lock struct {
+	// Hash is a content hash of all fetched tables.
+	Hash        string       `json:"hash"`
+	Formats     string       `json:"formats"`
+	Tabs        []TabHash    `json:"tabs"`
+	Keys        map[Lang]int `json:"keys"`
+	Version     string       `json:"version"`
+	GeneratedAt time.Time    `json:"generated_at"`
+}
+
+// tableHash returns a hex-encoded SHA-256 hash of the table contents.
+func tableHash(rows [][]RawCell) string {
+	data, _ := json.Marshal(rows)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewLock computes content hashes of the fetched tables.
+func NewLock(rawFormats [][]RawCell, localizationTabs []Tab) *Lock {
+	lock := &Lock{Formats: tableHash(rawFormats)}
+
+	h := sha256.New()
+	h.Write([]byte(lock.Formats))
+	for _, tab := range localizationTabs {
+		tabHash := TabHash{Name: tab.Name, Hash: tableHash(tab.Rows)}
+		lock.Tabs = append(lock.Tabs, tabHash)
+		h.Write([]byte(tabHash.Name))
+		h.Write([]byte(tabHash.Hash))
+	}
+	lock.Hash = hex.EncodeToString(h.Sum(nil))
+
+	return lock
+}
+
+// countKeys returns a number of localized keys for each language.
+func countKeys(localizations Localizations) map[Lang]int {
+	counts := map[Lang]int{}
+	for _, keyLoc := range localizations {
+		for lang, value := range keyLoc {
+			if value != "" {
+				counts[lang]++
+			}
+		}
+	}
+	return counts
+}
+
+func ReadLock(filePath string) (*Lock, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf(`can't parse lock file "%v": %w`, filePath, err)
+	}
+	return &lock, nil
+}
+
+// WriteLock atomically writes the lock file unless it already has the same contents. The generation time of the existing
+// lock file is kept while the source data hash stays the same, so that the file doesn't change on every run.
+func WriteLock(filePath string, lock *Lock) error {
+	l := *lock
+	if previous, err := ReadLock(filePath); err == nil && previous.Hash == l.Hash {
+		l.GeneratedAt = previous.GeneratedAt
+	}
+
+	data, err := json.MarshalIndent(&l, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = WriteFileIfChanged(filePath, append(data, '\n'))
+	return err
+}
+
+// Changes lists the differences between the source data recorded in the lock and the current one.
+func (l *Lock) Changes(current *Lock) []string {
+	if l.Hash == current.Hash {
+		return nil
+	}
+
+	var changes []string
+	if l.Formats != current.Formats {
+		changes = append(changes, `formats changed`)
+	}
+
+	oldTabs := map[string]string{}
+	for _, tab := range l.Tabs {
+		oldTabs[tab.Name] = tab.Hash
+	}
+	newTabs := map[string]bool{}
+	for _, tab := range current.Tabs {
+		newTabs[tab.Name] = true
+		if hash, ok := oldTabs[tab.Name]; !ok {
+			changes = append(changes, fmt.Sprintf(`"%v" tab added`, tab.Name))
+		} else if hash != tab.Hash {
+			changes = append(changes, fmt.Sprintf(`"%v" tab changed`, tab.Name))
+		}
+	}
+	for _, tab := range l.Tabs {
+		if !newTabs[tab.Name] {
+			changes = append(changes, fmt.Sprintf(`"%v" tab removed`, tab.Name))
+		}
+	}
+
+	if len(changes) == 0 {
+		changes = append(changes, `tabs reordered`)
+	}
+	return changes
+}
+
+// Check fetches the source data and compares it with the one recorded in the lock file.
+func Check(source Source, lockFilePath string) error {
+	lock, err := ReadLock(lockFilePath)
+	if err != nil {
+		return fmt.Errorf(`can't read lock file, reason: %w`, err)
+	}
+
+	rawFormats, localizationTabs, err := fetchEverythingRaw(source)
+	if err != nil {
+		return fmt.Errorf(`can't fetch data, reason: %w`, err)
+	}
+
+	if changes := lock.Changes(NewLock(rawFormats, localizationTabs)); len(changes) > 0 {
+		return &sourceChangedError{generatedAt: lock.GeneratedAt, changes: changes}
+	}
+	return nil
+}
+
+type sourceChangedError struct {
+	generatedAt time.Time
+	changes     []string
+}
+
+func (e *sourceChangedError) Error() string {
+	return fmt.Sprintf(`source data changed since the last generation at %v: %v`, e.generatedAt.Format(time.RFC3339), strings.Join(e.changes, ", "))
+}
@@ -0,0 +1,72 @@
+package goloc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockChanges(t *testing.T) {
+	formats := [][]RawCell{{"format", "ios"}, {"int", "d"}}
+	tabs := []Tab{
+		{Name: "onboarding", Rows: [][]RawCell{{"key", "lang_en"}, {"title", "Title"}}},
+		{Name: "checkout", Rows: [][]RawCell{{"key", "lang_en"}, {"pay", "Pay"}}},
+	}
+	lock := NewLock(formats, tabs)
+
+	assert.Equal(t, lock.Hash, NewLock(formats, tabs).Hash)
+	assert.Empty(t, lock.Changes(NewLock(formats, tabs)))
+
+	changedFormats := [][]RawCell{{"format", "ios"}, {"int", "ld"}}
+	assert.Equal(t, []string{`formats changed`}, lock.Changes(NewLock(changedFormats, tabs)))
+
+	changedTabs := []Tab{
+		{Name: "onboarding", Rows: [][]RawCell{{"key", "lang_en"}, {"title", "New title"}}},
+		{Name: "profile", Rows: [][]RawCell{{"key", "lang_en"}}},
+	}
+	assert.Equal(t, []string{`"onboarding" tab changed`, `"profile" tab added`, `"checkout" tab removed`}, lock.Changes(NewLock(formats, changedTabs)))
+
+	reorderedTabs := []Tab{tabs[1], tabs[0]}
+	assert.Equal(t, []string{`tabs reordered`}, lock.Changes(NewLock(formats, reorderedTabs)))
+}
+
+func TestCountKeys(t *testing.T) {
+	loc := Localizations{
+		"title": {"en": "Title", "ru": "Заголовок"},
+		"pay":   {"en": "Pay", "ru": ""},
+	}
+	assert.Equal(t, map[Lang]int{"en": 2, "ru": 1}, countKeys(loc))
+}
+
+func TestWriteLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "locks", "goloc.lock")
+	first := time.Date(2021, 3, 15, 10, 0, 0, 0, time.UTC)
+	assert.NoError(t, WriteLock(filePath, &Lock{Hash: "a", Version: "1.0", GeneratedAt: first}))
+	info, err := os.Stat(filePath)
+	assert.NoError(t, err)
+
+	// Unchanged source data keeps the generation time and the file itself
+	lock := &Lock{Hash: "a", Version: "1.0", GeneratedAt: first.Add(time.Hour)}
+	assert.NoError(t, WriteLock(filePath, lock))
+	assert.Equal(t, first.Add(time.Hour), lock.GeneratedAt)
+	saved, err := ReadLock(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, first, saved.GeneratedAt)
+	newInfo, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, info.ModTime(), newInfo.ModTime())
+
+	assert.NoError(t, WriteLock(filePath, &Lock{Hash: "b", Version: "1.0", GeneratedAt: first.Add(time.Hour)}))
+	saved, err = ReadLock(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", saved.Hash)
+	assert.Equal(t, first.Add(time.Hour), saved.GeneratedAt)
+}
@@ -0,0 +1,140 @@
+package goloc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestFileName is a name of the file in the resources directory which lists all files generated by goloc.
+const ManifestFileName = `.goloc-manifest.json`
+
+// manifest lists generated files relative to the resources directory.
+type manifest struct {
+	Files []string `json:"files"`
+}
+
+func readManifest(dir ResDir) (*manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFileName))
+	if os.IsNotExist(err) {
+		return &manifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for _, rel := range m.Files {
+		// Files outside of the resources directory must never be removed
+		if _, err := GeneratedFilePath(dir, filepath.FromSlash(rel)); err != nil {
+			return nil, err
+		}
+	}
+	return &m, nil
+}
+
+func writeManifest(dir ResDir, m *manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = WriteFileIfChanged(filepath.Join(dir, ManifestFileName), append(data, '\n'))
+	return err
+}
+
+// updateManifest records the files written during this run in the manifest and returns the files generated
+// previously which aren't produced anymore. If prune is true, such files are removed (along with the directories
+// left empty) and listed in summary.Removed. Merged files (see WriteSummary.Merged) contain hand-written entries,
+// so they're never recorded in the manifest.
+func updateManifest(dir ResDir, summary *WriteSummary, prune bool) (stale []string, error error) {
+	previous, err := readManifest(dir)
+	if err != nil {
+		return nil, &invalidManifestError{err: err}
+	}
+
+	merged := map[string]bool{}
+	for _, filePath := range summary.Merged {
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return nil, err
+		}
+		merged[filepath.ToSlash(rel)] = true
+	}
+
+	current := &manifest{Files: []string{}}
+	produced := map[string]bool{}
+	for _, files := range [][]string{summary.Written, summary.Unchanged} {
+		for _, filePath := range files {
+			rel, err := filepath.Rel(dir, filePath)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := GeneratedFilePath(dir, rel); err != nil {
+				// Files outside of the resources directory (e.g. the default localization file) aren't tracked
+				continue
+			}
+			rel = filepath.ToSlash(rel)
+			produced[rel] = true
+			if !merged[rel] {
+				current.Files = append(current.Files, rel)
+			}
+		}
+	}
+	sort.Strings(current.Files)
+
+	for _, rel := range previous.Files {
+		if produced[rel] || merged[rel] {
+			continue
+		}
+		filePath, err := GeneratedFilePath(dir, filepath.FromSlash(rel))
+		if err != nil {
+			return nil, &invalidManifestError{err: err}
+		}
+		if _, err := os.Stat(filePath); err != nil {
+			continue
+		}
+		if !prune {
+			stale = append(stale, filePath)
+			// Keep tracking the stale file, so that it can be pruned later
+			current.Files = append(current.Files, rel)
+			continue
+		}
+		if err := os.Remove(filePath); err != nil {
+			return nil, err
+		}
+		removeEmptyDirs(dir, filepath.Dir(filePath))
+		summary.Removed = append(summary.Removed, filePath)
+	}
+	sort.Strings(current.Files)
+	summary.sort()
+
+	return stale, writeManifest(dir, current)
+}
+
+// removeEmptyDirs removes a given directory and its parents up to the resources directory while they're empty.
+func removeEmptyDirs(resDir ResDir, dir string) {
+	resDir = filepath.Clean(resDir)
+	for dir = filepath.Clean(dir); dir != resDir && dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if rel, err := filepath.Rel(resDir, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+type invalidManifestError struct {
+	err error
+}
+
+func (e *invalidManifestError) Error() string {
+	return fmt.Sprintf(`can't read "%v": %v`, ManifestFileName, e.err)
+}
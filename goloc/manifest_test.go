@@ -0,0 +1,144 @@
+package goloc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	en := filepath.Join(dir, "values-en", "strings.xml")
+	ru := filepath.Join(dir, "values-ru", "strings.xml")
+	manual := filepath.Join(dir, "values-ru", "manual.xml")
+	for _, p := range []string{en, ru} {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), os.ModePerm))
+		assert.Nil(t, ioutil.WriteFile(p, nil, 0644))
+	}
+
+	stale, err := updateManifest(dir, &WriteSummary{Written: []string{en, ru}}, false)
+	assert.Nil(t, err)
+	assert.Empty(t, stale)
+
+	// "ru" isn't produced anymore, but it's kept until pruned
+	summary := &WriteSummary{Unchanged: []string{en}}
+	stale, err = updateManifest(dir, summary, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ru}, stale)
+	assert.Empty(t, summary.Removed)
+	assert.FileExists(t, ru)
+
+	// Files not created by goloc are never removed
+	assert.Nil(t, ioutil.WriteFile(manual, nil, 0644))
+	summary = &WriteSummary{Unchanged: []string{en}}
+	stale, err = updateManifest(dir, summary, true)
+	assert.Nil(t, err)
+	assert.Empty(t, stale)
+	assert.Equal(t, []string{ru}, summary.Removed)
+	assert.NoFileExists(t, ru)
+	assert.FileExists(t, manual)
+
+	// Directories left empty are removed
+	assert.Nil(t, os.Remove(manual))
+	assert.Nil(t, ioutil.WriteFile(ru, nil, 0644))
+	_, err = updateManifest(dir, &WriteSummary{Written: []string{en, ru}}, false)
+	assert.Nil(t, err)
+	_, err = updateManifest(dir, &WriteSummary{Unchanged: []string{en}}, true)
+	assert.Nil(t, err)
+	assert.NoDirExists(t, filepath.Dir(ru))
+	assert.DirExists(t, filepath.Dir(en))
+
+	m, err := readManifest(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"values-en/strings.xml"}, m.Files)
+}
+
+type mergerMockPlatform struct {
+	*mockPlatform
+}
+
+func (mergerMockPlatform) LocalizationFilePath(lang Lang, namespace Namespace, resDir ResDir) string {
+	return filepath.Join(resDir, "values-"+lang, "strings.xml")
+}
+
+func (mergerMockPlatform) LocalizedString(args *LocalizedStringArgs) string {
+	return args.Key + "=" + args.Value + "\n"
+}
+
+func (mergerMockPlatform) Merge(args *MergeArgs) (string, []Key, error) {
+	region := "begin\n" + args.Strings + "end\n"
+	if merged, ok := ReplaceRegion(args.Existing, "begin", "end", region); ok {
+		return merged, nil, nil
+	}
+	return args.Existing + region, nil, nil
+}
+
+func TestUpdateManifestKeepsMergedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	platform := mergerMockPlatform{newMockPlatform(nil)}
+	de := filepath.Join(dir, "values-de", "strings.xml")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(de), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(de, []byte("manual=Handgeschrieben\n"), 0644))
+
+	loc := Localizations{"title": {"en": "Title", "de": "Titel"}}
+	summary, err := WriteLocalizations(platform, dir, loc, nil, nil, nil, "", "", "", true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{de, filepath.Join(dir, "values-en", "strings.xml")}, summary.Merged)
+	_, err = updateManifest(dir, summary, false)
+	assert.Nil(t, err)
+
+	m, err := readManifest(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, m.Files)
+
+	// "de" isn't produced anymore, but the merged file with the hand-written entries is never pruned
+	loc = Localizations{"title": {"en": "Title"}}
+	summary, err = WriteLocalizations(platform, dir, loc, nil, nil, nil, "", "", "", true, nil)
+	assert.Nil(t, err)
+	stale, err := updateManifest(dir, summary, true)
+	assert.Nil(t, err)
+	assert.Empty(t, stale)
+	assert.Empty(t, summary.Removed)
+
+	contents, err := ioutil.ReadFile(de)
+	assert.Nil(t, err)
+	assert.Equal(t, "manual=Handgeschrieben\nbegin\ntitle=Titel\nend\n", string(contents))
+}
+
+func TestUpdateManifestOutsideOfResDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "goloc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, "res")
+	outside := filepath.Join(root, "en.php")
+	assert.Nil(t, os.MkdirAll(dir, os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(outside, nil, 0644))
+
+	// Files written outside of the resources directory aren't tracked
+	_, err = updateManifest(dir, &WriteSummary{Written: []string{outside}}, false)
+	assert.Nil(t, err)
+	m, err := readManifest(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, m.Files)
+	contents, err := ioutil.ReadFile(filepath.Join(dir, ManifestFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"files\": []\n}\n", string(contents))
+
+	// Manifest entries pointing outside of the resources directory are rejected
+	for _, rel := range []string{"../en.php", "..", "/en.php"} {
+		assert.Nil(t, writeManifest(dir, &manifest{Files: []string{rel}}))
+		_, err = updateManifest(dir, &WriteSummary{}, true)
+		assert.IsType(t, &invalidManifestError{}, err)
+		assert.FileExists(t, outside)
+	}
+}
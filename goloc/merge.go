@@ -0,0 +1,117 @@
+package goloc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MergeArgs encapsulates arguments to a function that merges generated localized strings into an existing file.
+type MergeArgs struct {
+	Lang      Lang
+	Namespace Namespace
+	// Existing is a content of the existing localization file. If there's no such file yet, it's an empty file
+	// consisting of the platform header and footer.
+	Existing string
+	// Strings are the generated localized strings.
+	Strings string
+	// Keys are the keys of the generated localized strings.
+	Keys []Key
+}
+
+// Merger is implemented by platforms that can merge generated localized strings into existing files, preserving the
+// hand-written entries. Generated strings are expected to be placed into a marked region which is replaced on every
+// run, everything outside of it is left intact.
+type Merger interface {
+	// Merge returns a merged file content along with the keys of the hand-written entries colliding with the
+	// generated ones.
+	Merge(args *MergeArgs) (merged string, conflicts []Key, err error)
+}
+
+// ReplaceRegion replaces the lines containing begin and end markers, along with everything between them, with region.
+// It returns false if existing doesn't contain such a region.
+func ReplaceRegion(existing string, begin string, end string, region string) (string, bool) {
+	start, stop, ok := findRegion(existing, begin, end)
+	if !ok {
+		return existing, false
+	}
+	return existing[:start] + region + existing[stop:], true
+}
+
+// OutsideRegion returns existing without the lines containing begin and end markers and everything between them.
+func OutsideRegion(existing string, begin string, end string) string {
+	result, _ := ReplaceRegion(existing, begin, end, "")
+	return result
+}
+
+func findRegion(existing string, begin string, end string) (start int, stop int, ok bool) {
+	b := strings.Index(existing, begin)
+	if b < 0 {
+		return
+	}
+	e := strings.Index(existing[b+len(begin):], end)
+	if e < 0 {
+		return
+	}
+	e += b + len(begin) + len(end)
+
+	start = strings.LastIndex(existing[:b], "\n") + 1
+	stop = len(existing)
+	if i := strings.Index(existing[e:], "\n"); i >= 0 {
+		stop = e + i + 1
+	}
+	return start, stop, true
+}
+
+// mergeInput holds the data required to merge generated localized strings into the existing files.
+type mergeInput struct {
+	merger    Merger
+	skeletons map[localizationFile]*bytes.Buffer
+	keys      map[localizationFile][]Key
+}
+
+func (m *mergeInput) merge(file localizationFile, filePath string, buf *bytes.Buffer) (*bytes.Buffer, []Key, error) {
+	existing, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		existing, err = m.skeletons[file].Bytes(), nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged, conflicts, err := m.merger.Merge(&MergeArgs{
+		Lang:      file.lang,
+		Namespace: file.namespace,
+		Existing:  string(existing),
+		Strings:   buf.String(),
+		Keys:      m.keys[file],
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf(`can't merge "%v": %w`, filePath, err)
+	}
+	return bytes.NewBufferString(merged), conflicts, nil
+}
+
+type mergeConflictError struct {
+	conflicts map[string][]Key
+}
+
+func (e *mergeConflictError) Error() string {
+	var files []string
+	for filePath, keys := range e.conflicts {
+		files = append(files, fmt.Sprintf(`%v (%v)`, filePath, strings.Join(keys, ", ")))
+	}
+	sort.Strings(files)
+	return fmt.Sprintf(`hand-written entries collide with the generated ones: %v`, strings.Join(files, "; "))
+}
+
+type mergeNotSupportedError struct {
+	platform string
+}
+
+func (e *mergeNotSupportedError) Error() string {
+	return fmt.Sprintf(`platform "%v" doesn't support merging into existing files`, e.platform)
+}
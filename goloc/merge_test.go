@@ -0,0 +1,29 @@
+package goloc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceRegion(t *testing.T) {
+	existing := "<resources>\n\t<string name=\"a\">A</string>\n\t<!-- begin -->\n\t<string name=\"b\">B</string>\n\t<!-- end -->\n</resources>\n"
+
+	result, ok := ReplaceRegion(existing, "<!-- begin -->", "<!-- end -->", "\t<!-- begin -->\n\t<string name=\"c\">C</string>\n\t<!-- end -->\n")
+	assert.True(t, ok)
+	assert.Equal(t, "<resources>\n\t<string name=\"a\">A</string>\n\t<!-- begin -->\n\t<string name=\"c\">C</string>\n\t<!-- end -->\n</resources>\n", result)
+
+	assert.Equal(t, "<resources>\n\t<string name=\"a\">A</string>\n</resources>\n", OutsideRegion(existing, "<!-- begin -->", "<!-- end -->"))
+
+	_, ok = ReplaceRegion("<resources>\n</resources>\n", "<!-- begin -->", "<!-- end -->", "")
+	assert.False(t, ok)
+
+	// The end marker must follow the begin marker
+	_, ok = ReplaceRegion("<!-- end -->\n<!-- begin -->\n", "<!-- begin -->", "<!-- end -->", "")
+	assert.False(t, ok)
+
+	// The region may end at the end of the file
+	result, ok = ReplaceRegion("a\n/* begin */\nb\n/* end */", "/* begin */", "/* end */", "c\n")
+	assert.True(t, ok)
+	assert.Equal(t, "a\nc\n", result)
+}
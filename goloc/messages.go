@@ -0,0 +1,81 @@
+package goloc
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/s0nerik/goloc/goloc/icu"
+)
+
+// complexArgRegexp matches the beginning of a select, plural or selectordinal argument. Localized strings containing
+// such arguments are parsed as ICU messages (see icu package).
+var complexArgRegexp = regexp.MustCompile(`\{\s*[^\s{},]+\s*,\s*(?:select|plural|selectordinal)\s*,`)
+
+// MessageWriter can be implemented by a platform to support ICU messages with select, plural and selectordinal
+// arguments. LocalizedString receives the parsed message in LocalizedStringArgs.Message.
+type MessageWriter interface {
+	// Returns nil if a message can be written by the platform and non-nil error otherwise.
+	ValidateMessage(message icu.Message) error
+}
+
+// keyMessages parses the localized strings of a key as ICU messages if any of them contains a complex argument.
+// Messages are nil otherwise. Each language must use the same set of arguments, args are ordered by their first
+// occurrence in the first language.
+func keyMessages(
+	platform Platform,
+	tab string,
+	line int,
+	row []string,
+	key Key,
+	langColumns langColumns,
+	emptyLocalizationRegexp *regexp.Regexp,
+) (messages map[Lang]icu.Message, args []icu.Arg, err error) {
+	var columns []int
+	isMessage := false
+	for i := range langColumns {
+		if i >= len(row) || emptyLocalizationRegexp.MatchString(strings.TrimSpace(row[i])) {
+			continue
+		}
+		columns = append(columns, i)
+		isMessage = isMessage || complexArgRegexp.MatchString(row[i])
+	}
+	if !isMessage {
+		return
+	}
+	sort.Ints(columns)
+
+	var argNames []string
+	messages = map[Lang]icu.Message{}
+	for _, col := range columns {
+		cell := *NewCell(tab, uint(line), uint(col))
+		message, parseErr := icu.Parse(strings.TrimSpace(row[col]))
+		if parseErr != nil {
+			return nil, nil, &messageSyntaxError{cell: cell, reason: parseErr}
+		}
+
+		w, ok := platform.(MessageWriter)
+		if !ok {
+			return nil, nil, &messageNotSupportedError{cell: cell, platformName: platform.Names()[0]}
+		}
+		if validationErr := w.ValidateMessage(message); validationErr != nil {
+			return nil, nil, &messageInvalidError{cell: cell, platformName: platform.Names()[0], reason: validationErr}
+		}
+
+		var names []string
+		for _, arg := range message.Args() {
+			names = append(names, arg.Name)
+		}
+		sort.Strings(names)
+		if args == nil {
+			args = message.Args()
+			argNames = names
+		} else if !reflect.DeepEqual(names, argNames) {
+			return nil, nil, newFormatArgsDifferentError(tab, line, col, key, langColumns[col])
+		}
+
+		messages[langColumns[col]] = message
+	}
+	return
+}
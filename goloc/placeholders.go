@@ -0,0 +1,73 @@
+package goloc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/s0nerik/goloc/goloc/re"
+)
+
+// placeholderRegexp matches the contents of a placeholder: "[<position>$][<name>:]<format name>",
+// e.g. "x", "count:int" or "2$count:int".
+var placeholderRegexp = regexp.MustCompile(`^(?:([1-9]\d*)\$)?(?:([a-zA-Z_][a-zA-Z0-9_]*):)?(.*)$`)
+
+// inlinePlaceholderRegexp matches the contents of an inline typed placeholder: "[<position>$]<name>, <type>[, <style>]",
+// e.g. "count, number" or "price, number, currency".
+var inlinePlaceholderRegexp = regexp.MustCompile(`^(?:([1-9]\d*)\$)?\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*,\s*([a-z]+)\s*(?:,\s*([a-z]+)\s*)?$`)
+
+// placeholder represents a format occurrence in a localized string.
+type placeholder struct {
+	// Name of the argument. Empty for the unnamed placeholders (e.g. "{x}").
+	name string
+	// Format name.
+	format FormatKey
+	// Explicit 1-based position of the argument. Zero if not specified.
+	position int
+}
+
+// arg returns a name to refer to the placeholder argument in the error messages.
+func (p placeholder) arg() string {
+	if p.name != "" {
+		return p.name
+	}
+	return fmt.Sprintf("{%v}", p.format)
+}
+
+// parsePlaceholder parses the contents of a placeholder. Format name of an inline typed placeholder is its type
+// followed by the style, if any (e.g. "number, currency", see InlineFormatCurrency).
+func parsePlaceholder(str string) (p placeholder) {
+	if m := inlinePlaceholderRegexp.FindStringSubmatch(str); m != nil {
+		p.position, _ = strconv.Atoi(m[1])
+		p.name = m[2]
+		p.format = m[3]
+		if m[4] != "" {
+			p.format += ", " + m[4]
+		}
+		return
+	}
+
+	m := placeholderRegexp.FindStringSubmatch(str)
+	p.position, _ = strconv.Atoi(m[1])
+	p.name = m[2]
+	p.format = m[3]
+	return
+}
+
+// placeholders returns all placeholders of a string along with the identifiers of their arguments.
+// Named placeholders with the same name refer to the same argument, each unnamed placeholder refers to a separate
+// argument identified by its format name and its occurrence number among the unnamed placeholders with the same format.
+func placeholders(str string) (result []placeholder, ids []string) {
+	occurrences := map[FormatKey]int{}
+	for _, m := range re.FormatRegexp().FindAllStringSubmatch(str, -1) {
+		p := parsePlaceholder(m[1])
+		id := p.name
+		if id == "" {
+			occurrences[p.format]++
+			id = fmt.Sprintf("{%v}#%v", p.format, occurrences[p.format])
+		}
+		result = append(result, p)
+		ids = append(ids, id)
+	}
+	return
+}
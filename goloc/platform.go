@@ -1,32 +1,66 @@
 package goloc
 
-import "time"
+import (
+	"reflect"
+	"time"
+
+	"github.com/s0nerik/goloc/goloc/icu"
+)
 
 // LocalizedStringArgs encapsulates arguments to a function that returns the actual localized string for a given platform.
 type LocalizedStringArgs struct {
 	Index      int
 	IsLast     bool
 	Lang       Lang
+	Namespace  Namespace
 	Key        Key
 	Value      string
 	FormatArgs []string
+	// Description of the localized string. Empty if not specified.
+	Description string
+	// Message is the parsed localized string if it's an ICU message (see MessageWriter), nil otherwise.
+	// Value contains the message text with the replaced special characters in this case.
+	Message icu.Message
+	// MessageArgs are arguments of the ICU message shared by all languages.
+	MessageArgs []icu.Arg
+	// Messages are the ICU messages of the key in all languages. Nil if the localized string isn't an ICU message.
+	Messages map[Lang]icu.Message
+	// IsArray is true if the localized string is a string array (see ArrayWriter).
+	IsArray bool
+	// Items of the string array. Value contains the items separated by newlines in this case.
+	Items []string
 }
 
 // FormatStringArgs encapsulates arguments to a function that returns the actual format specification for a given platform.
 type FormatStringArgs struct {
+	// Index of the argument. Placeholders referring to the same argument have the same index.
 	Index  int
+	Name   FormatKey
 	Format string
+	// ArgName is a name of the argument (e.g. "count" for "{count:int}"). Empty for the unnamed placeholders.
+	ArgName string
+	// Occurrence is a 1-based number of an unnamed argument among the unnamed arguments with the same format, ordered by
+	// their indices (e.g. 2 for the second argument of "{str} {str}"). Zero for the named arguments.
+	Occurrence int
+	// Reordered is true if the arguments occur in the localized string in a different order than their indices.
+	// Platforms which don't use explicit positions by default should specify them in this case.
+	Reordered bool
 }
 
 // HeaderArgs encapsulates arguments to a function that returns a localization file header for a given platform.
 type HeaderArgs struct {
-	Lang Lang
-	Time time.Time
+	Lang      Lang
+	Namespace Namespace
+	Time      time.Time
+	ResDir    ResDir
+	// Hash is a content hash of the source data (see Lock).
+	Hash string
 }
 
 // FooterArgs encapsulates arguments to a function that returns a localization file footer for a given platform.
 type FooterArgs struct {
-	Lang Lang
+	Lang      Lang
+	Namespace Namespace
 }
 
 // Platform represents an object responsible for specifying a format of the resulting localization file.
@@ -34,8 +68,9 @@ type Platform interface {
 	// Returns platform names that can be used to identify it in the sheet.
 	Names() []string
 
-	// Returns a full relative path to localization file for a given language.
-	LocalizationFilePath(lang Lang, resDir ResDir) string
+	// Returns a full relative path to localization file for a given language and namespace.
+	// Namespace is empty unless localized strings are split into multiple files.
+	LocalizationFilePath(lang Lang, namespace Namespace, resDir ResDir) string
 
 	// Returns header text. Returned string can be empty. Newlines must be included here if localization format requires them.
 	Header(args *HeaderArgs) string
@@ -58,6 +93,29 @@ type Platform interface {
 	ReplacementChars() map[string]string
 }
 
+// ErrorReporter can be implemented by a platform whose methods can fail (e.g. when rendering user-supplied templates).
+// A reported error aborts writing before any localization file is replaced.
+type ErrorReporter interface {
+	// Returns the first error occurred in the platform methods or nil.
+	Err() error
+}
+
 type FallbackStringWriter interface {
 	FallbackString(args *LocalizedStringArgs) string
-}
\ No newline at end of file
+}
+
+// PlatformInterfaces lists the optional interfaces a Platform can implement, in the order they are reported by the
+// platforms command. A new optional interface must be added here.
+var PlatformInterfaces = []reflect.Type{
+	reflect.TypeOf((*Preprocessor)(nil)).Elem(),
+	reflect.TypeOf((*Postprocessor)(nil)).Elem(),
+	reflect.TypeOf((*FallbackStringWriter)(nil)).Elem(),
+	reflect.TypeOf((*Generator)(nil)).Elem(),
+	reflect.TypeOf((*ErrorReporter)(nil)).Elem(),
+	reflect.TypeOf((*Merger)(nil)).Elem(),
+	reflect.TypeOf((*KeyValidator)(nil)).Elem(),
+	reflect.TypeOf((*InlineFormatter)(nil)).Elem(),
+	reflect.TypeOf((*MessageWriter)(nil)).Elem(),
+	reflect.TypeOf((*ArrayWriter)(nil)).Elem(),
+	reflect.TypeOf((*ExtraFilesGenerator)(nil)).Elem(),
+}
@@ -13,8 +13,8 @@ func (p *mockPlatform) Names() []string {
 	return args.Get(0).([]string)
 }
 
-func (p *mockPlatform) LocalizationFilePath(lang Lang, resDir ResDir) string {
-	args := p.Called(lang, resDir)
+func (p *mockPlatform) LocalizationFilePath(lang Lang, namespace Namespace, resDir ResDir) string {
+	args := p.Called(lang, namespace, resDir)
 	return args.String(0)
 }
 
@@ -54,7 +54,7 @@ func newMockPlatform(customMocksProvider func(p *mockPlatform)) *mockPlatform {
 		customMocksProvider(p)
 	}
 	p.On("Names").Return([]string{"mock"})
-	p.On("LocalizationFilePath", mock.AnythingOfType("Lang"), mock.AnythingOfType("ResDir")).Return("")
+	p.On("LocalizationFilePath", mock.AnythingOfType("Lang"), mock.AnythingOfType("Namespace"), mock.AnythingOfType("ResDir")).Return("")
 	p.On("Header", mock.AnythingOfType("*goloc.HeaderArgs")).Return("")
 	p.On("LocalizedString", mock.AnythingOfType("*goloc.LocalizedStringArgs")).Return("")
 	p.On("Footer", mock.AnythingOfType("*goloc.FooterArgs")).Return("")
@@ -5,6 +5,7 @@ type PostprocessArgs struct {
 	Localizations       Localizations
 	Formats             Formats
 	FormatArgs          LocalizationFormatArgs
+	Meta                LocalizationMeta
 	ResDir              ResDir
 	DefaultLocalization Lang
 }
@@ -6,4 +6,16 @@ type Source interface {
 
 	Formats() ([][]RawCell, error)
 	Localizations() ([][]RawCell, error)
-}
\ No newline at end of file
+}
+
+// Tab represents a named table of raw cells, e.g. a single spreadsheet tab.
+type Tab struct {
+	Name string      `json:"name"`
+	Rows [][]RawCell `json:"rows"`
+}
+
+// MultiTabSource is implemented by sources that can provide localizations split into multiple tabs.
+// If a source implements it, Localizations and LocalizationsDocumentName aren't used.
+type MultiTabSource interface {
+	LocalizationTabs() ([]Tab, error)
+}
@@ -4,51 +4,51 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
-func newWriter(
-	platform Platform,
-	dir ResDir,
-	lang Lang,
-	defLocLang Lang,
-	defLocPath string,
-) (file *os.File, writer *bufio.Writer, error error) {
-	// Get actual resource file dir and name
-	resDir, fileName, err := localizationFilePath(platform, dir, lang, defLocLang, defLocPath)
-	if err != nil {
-		error = err
-		return
-	}
-
+// newWriter creates a temporary file next to filePath, which is meant to replace it after being written successfully.
+func newWriter(filePath string) (file *os.File, writer *bufio.Writer, error error) {
 	// Create all intermediate directories
-	err = os.MkdirAll(resDir, os.ModePerm)
+	err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
 	if err != nil {
 		error = err
 		return
 	}
 
-	// Create actual localization file
-	file, err = os.Create(filepath.Join(resDir, fileName))
+	// Create a temporary file in the same directory, so that it can be renamed into the actual localization file
+	file, err = ioutil.TempFile(filepath.Dir(filePath), fmt.Sprintf(".%s.*.tmp", filepath.Base(filePath)))
 	if err != nil {
 		error = err
 		return
 	}
 
-	// Create a new writer for the localization file
+	// Create a new writer for the temporary file
 	writer = bufio.NewWriter(file)
 
 	return
 }
 
-func writeHeaders(platform Platform, buffers map[Lang]*bytes.Buffer, t time.Time) error {
+// localizationFile identifies a single localization file.
+type localizationFile struct {
+	lang      Lang
+	namespace Namespace
+}
+
+func writeHeaders(platform Platform, dir ResDir, buffers map[localizationFile]*bytes.Buffer, t time.Time, hash string) error {
 	headerArgs := &HeaderArgs{}
-	for lang, buf := range buffers {
-		headerArgs.Lang = lang
+	for file, buf := range buffers {
+		headerArgs.Lang = file.lang
+		headerArgs.Namespace = file.namespace
 		headerArgs.Time = t
+		headerArgs.ResDir = dir
+		headerArgs.Hash = hash
 		if _, err := buf.WriteString(platform.Header(headerArgs)); err != nil {
 			return err
 		}
@@ -56,10 +56,11 @@ func writeHeaders(platform Platform, buffers map[Lang]*bytes.Buffer, t time.Time
 	return nil
 }
 
-func writeFooters(platform Platform, buffers map[Lang]*bytes.Buffer) error {
+func writeFooters(platform Platform, buffers map[localizationFile]*bytes.Buffer) error {
 	footerArgs := &FooterArgs{}
-	for lang, buf := range buffers {
-		footerArgs.Lang = lang
+	for file, buf := range buffers {
+		footerArgs.Lang = file.lang
+		footerArgs.Namespace = file.namespace
 		if _, err := buf.WriteString(platform.Footer(footerArgs)); err != nil {
 			return err
 		}
@@ -67,70 +68,355 @@ func writeFooters(platform Platform, buffers map[Lang]*bytes.Buffer) error {
 	return nil
 }
 
+// writeBuffers writes buffers into the corresponding localization files along with the extra files. If merge is
+// specified, buffers are merged into the existing files instead of replacing them.
 func writeBuffers(
 	platform Platform,
 	dir ResDir,
-	localizations Localizations,
 	defLocLang Lang,
 	defLocPath string,
-	buffers map[Lang]*bytes.Buffer,
-) error {
-	ch := make(chan error, len(buffers))
-	for lang, buf := range buffers {
-		go func(lang Lang, buf *bytes.Buffer) {
-			file, writer, err := newWriter(platform, dir, lang, defLocLang, defLocPath)
+	buffers map[localizationFile]*bytes.Buffer,
+	merge *mergeInput,
+	extra []OutputFile,
+) (*WriteSummary, error) {
+	files := map[string]*bytes.Buffer{}
+	fileNamespaces := map[string]Namespace{}
+	conflicts := map[string][]Key{}
+	for file, buf := range buffers {
+		resDir, fileName, err := localizationFilePath(platform, dir, file.lang, file.namespace, defLocLang, defLocPath)
+		if err != nil {
+			return nil, err
+		}
+		filePath := filepath.Join(resDir, fileName)
+		if namespace, ok := fileNamespaces[filePath]; ok {
+			return nil, &sharedOutputFilePath{path: filePath, namespaces: []Namespace{namespace, file.namespace}}
+		}
+		fileNamespaces[filePath] = file.namespace
+
+		if merge != nil {
+			merged, fileConflicts, err := merge.merge(file, filePath, buf)
 			if err != nil {
-				ch <- err
-				return
+				return nil, err
+			}
+			if len(fileConflicts) > 0 {
+				conflicts[filePath] = fileConflicts
 			}
-			defer file.Close()
+			buf = merged
+		}
+		files[filePath] = buf
+	}
+	if len(conflicts) > 0 {
+		return nil, &mergeConflictError{conflicts: conflicts}
+	}
+	for _, f := range extra {
+		if len(f.Path) == 0 {
+			return nil, &emptyLocalizationFilePath{}
+		}
+		filePath := filepath.Clean(f.Path)
+		if _, ok := files[filePath]; ok {
+			return nil, &duplicateOutputFilePath{path: f.Path}
+		}
+		files[filePath] = bytes.NewBufferString(f.Contents)
+	}
+	if r, ok := platform.(ErrorReporter); ok {
+		if err := r.Err(); err != nil {
+			return nil, err
+		}
+	}
 
-			if _, err = writer.WriteString(buf.String()); err != nil {
-				ch <- err
-				return
+	summary, err := writeFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	if merge != nil {
+		// Extra files are always generated as a whole
+		for filePath := range fileNamespaces {
+			summary.Merged = append(summary.Merged, filePath)
+		}
+		summary.sort()
+	}
+	return summary, nil
+}
+
+// WriteSummary lists the files affected by writing the localizations.
+type WriteSummary struct {
+	Written   []string
+	Unchanged []string
+	Removed   []string
+	// Merged lists the written and unchanged files which were merged with the hand-written entries (see Merger).
+	// Such files aren't tracked in the manifest, so they're never pruned.
+	Merged []string
+}
+
+func (s *WriteSummary) String() string {
+	return fmt.Sprintf("%d written, %d unchanged, %d removed", len(s.Written), len(s.Unchanged), len(s.Removed))
+}
+
+func (s *WriteSummary) sort() {
+	sort.Strings(s.Written)
+	sort.Strings(s.Unchanged)
+	sort.Strings(s.Removed)
+	sort.Strings(s.Merged)
+}
+
+// fileContentEquals reports whether a file exists and has exactly the given contents.
+func fileContentEquals(filePath string, contents []byte) bool {
+	existing, err := ioutil.ReadFile(filePath)
+	return err == nil && bytes.Equal(existing, contents)
+}
+
+// stagedFile is a fully written temporary file waiting to replace the target file.
+type stagedFile struct {
+	path       string
+	tempPath   string
+	backupPath string
+}
+
+// stageFile writes contents into a temporary file next to filePath. The temporary file is removed on failure.
+func stageFile(filePath string, contents []byte) (staged *stagedFile, error error) {
+	file, writer, err := newWriter(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if error != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	if _, error = writer.Write(contents); error != nil {
+		return
+	}
+	if error = writer.Flush(); error != nil {
+		return
+	}
+	if error = file.Sync(); error != nil {
+		return
+	}
+	if error = file.Close(); error != nil {
+		return
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if error = os.Chmod(file.Name(), mode); error != nil {
+		return
+	}
+
+	return &stagedFile{path: filePath, tempPath: file.Name()}, nil
+}
+
+func discardStagedFiles(files []*stagedFile) {
+	for _, f := range files {
+		os.Remove(f.tempPath)
+	}
+}
+
+// commitStagedFiles replaces target files with the staged ones. If any of the files can't be replaced, all already
+// replaced files are restored and the remaining temporary files are removed.
+func commitStagedFiles(files []*stagedFile) error {
+	for i, f := range files {
+		if err := f.commit(); err != nil {
+			for _, committed := range files[:i] {
+				committed.rollback()
 			}
-			if err = writer.Flush(); err != nil {
-				ch <- err
+			discardStagedFiles(files[i:])
+			return err
+		}
+	}
+
+	for _, f := range files {
+		if f.backupPath != "" {
+			os.Remove(f.backupPath)
+		}
+	}
+	return nil
+}
+
+func (f *stagedFile) commit() error {
+	if _, err := os.Stat(f.path); err == nil {
+		f.backupPath = f.tempPath + ".bak"
+		if err := os.Rename(f.path, f.backupPath); err != nil {
+			f.backupPath = ""
+			return err
+		}
+	}
+
+	if err := os.Rename(f.tempPath, f.path); err != nil {
+		if f.backupPath != "" {
+			os.Rename(f.backupPath, f.path)
+			f.backupPath = ""
+		}
+		return err
+	}
+	return nil
+}
+
+func (f *stagedFile) rollback() {
+	if f.backupPath != "" {
+		os.Rename(f.backupPath, f.path)
+	} else {
+		os.Remove(f.path)
+	}
+}
+
+// WriteFileIfChanged atomically replaces a file with the given contents unless it already has exactly the same
+// contents, so that its modification time is preserved. It returns true if the file was written.
+func WriteFileIfChanged(filePath string, contents []byte) (bool, error) {
+	if fileContentEquals(filePath, contents) {
+		return false, nil
+	}
+
+	staged, err := stageFile(filePath, contents)
+	if err != nil {
+		return false, err
+	}
+	if err := commitStagedFiles([]*stagedFile{staged}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeFiles writes all files with changed contents into temporary files first. The actual files are replaced only
+// if all of them were written successfully, so a failure never leaves the files partially updated.
+func writeFiles(files map[string]*bytes.Buffer) (*WriteSummary, error) {
+	type result struct {
+		filePath string
+		staged   *stagedFile
+		err      error
+	}
+
+	ch := make(chan result, len(files))
+	for filePath, buf := range files {
+		go func(filePath string, buf *bytes.Buffer) {
+			if fileContentEquals(filePath, buf.Bytes()) {
+				ch <- result{filePath: filePath}
 				return
 			}
-			if err = file.Sync(); err != nil {
-				ch <- err
-				return
+			staged, err := stageFile(filePath, buf.Bytes())
+			ch <- result{filePath, staged, err}
+		}(filePath, buf)
+	}
+
+	// Wait for all files to be staged, even if some of them failed
+	var staged []*stagedFile
+	var firstErr error
+	summary := &WriteSummary{}
+	for range files {
+		r := <-ch
+		switch {
+		case r.err != nil:
+			if firstErr == nil {
+				firstErr = r.err
 			}
-			ch <- nil
-		}(lang, buf)
+		case r.staged != nil:
+			staged = append(staged, r.staged)
+			summary.Written = append(summary.Written, r.filePath)
+		default:
+			summary.Unchanged = append(summary.Unchanged, r.filePath)
+		}
 	}
 
-	for range buffers {
-		if err := <-ch; err != nil {
-			return err
+	if firstErr != nil {
+		discardStagedFiles(staged)
+		return nil, firstErr
+	}
+	if err := commitStagedFiles(staged); err != nil {
+		return nil, err
+	}
+	summary.sort()
+
+	return summary, nil
+}
+
+// WriteGeneratedFiles writes files produced by a Generator into the resources directory.
+func WriteGeneratedFiles(dir ResDir, files []OutputFile) (*WriteSummary, error) {
+	buffers := map[string]*bytes.Buffer{}
+	for _, f := range files {
+		filePath, err := GeneratedFilePath(dir, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := buffers[filePath]; ok {
+			return nil, &duplicateOutputFilePath{path: f.Path}
 		}
+		buffers[filePath] = bytes.NewBufferString(f.Contents)
 	}
+	return writeFiles(buffers)
+}
 
-	return nil
+// GeneratedFilePath resolves a generated file path relative to the resources directory, making sure it doesn't point
+// outside of it.
+func GeneratedFilePath(dir ResDir, filePath string) (string, error) {
+	if len(filePath) == 0 {
+		return "", &emptyLocalizationFilePath{}
+	}
+	if filepath.IsAbs(filePath) {
+		return "", &invalidOutputFilePath{path: filePath, reason: "path must be relative to the resources directory"}
+	}
+	cleanPath := filepath.Clean(filePath)
+	if cleanPath == ".." || strings.HasPrefix(cleanPath, ".."+string(filepath.Separator)) {
+		return "", &invalidOutputFilePath{path: filePath, reason: "path must not point outside of the resources directory"}
+	}
+	return filepath.Join(dir, cleanPath), nil
 }
 
 // WriteLocalizations writes localization files into platform-defined directories.
+// Localized strings are written into a separate file for each language and namespace.
+// If merge is true, localized strings are merged into the existing files (see Merger).
+// Extra files (see ExtraFilesGenerator) are written along with the localization files.
 func WriteLocalizations(
 	platform Platform,
 	dir ResDir,
 	localizations Localizations,
 	formatArgs LocalizationFormatArgs,
+	meta LocalizationMeta,
+	namespaces LocalizationNamespaces,
 	defLocLang Lang,
 	defLocPath string,
-) (error error) {
-	locIndices := map[Lang]int{}
-	locCounts := localizations.Count()
+	hash string,
+	merge bool,
+	extra []OutputFile,
+) (summary *WriteSummary, error error) {
+	var mergeIn *mergeInput
+	if merge {
+		merger, ok := platform.(Merger)
+		if !ok {
+			return nil, &mergeNotSupportedError{platform: platform.Names()[0]}
+		}
+		mergeIn = &mergeInput{merger: merger, skeletons: map[localizationFile]*bytes.Buffer{}, keys: map[localizationFile][]Key{}}
+	}
+
+	locIndices := map[localizationFile]int{}
+	locCounts := map[localizationFile]int{}
 	locStringArgs := &LocalizedStringArgs{}
 
-	// Prepare string buffers for each language
-	buffers := map[Lang]*bytes.Buffer{}
-	for lang := range locCounts {
-		buffers[lang] = bytes.NewBufferString("")
+	for key, keyLoc := range localizations {
+		for lang := range keyLoc {
+			locCounts[localizationFile{lang, namespaces[key]}]++
+		}
+	}
+
+	// Prepare string buffers for each language and namespace
+	buffers := map[localizationFile]*bytes.Buffer{}
+	for file := range locCounts {
+		buffers[file] = bytes.NewBufferString("")
+	}
+
+	// Headers and footers are only used for new files when merging
+	skeletons := buffers
+	if mergeIn != nil {
+		for file := range locCounts {
+			mergeIn.skeletons[file] = bytes.NewBufferString("")
+		}
+		skeletons = mergeIn.skeletons
 	}
 
 	// Write headers
-	if error = writeHeaders(platform, buffers, time.Now()); error != nil {
+	if error = writeHeaders(platform, dir, skeletons, time.Now(), hash); error != nil {
 		return
 	}
 
@@ -138,52 +424,79 @@ func WriteLocalizations(
 	for _, key := range localizations.SortedKeys() {
 		keyLoc := localizations[key]
 		for lang, value := range keyLoc {
-			buf := buffers[lang]
+			file := localizationFile{lang, namespaces[key]}
+			buf := buffers[file]
 
 			// Update arguments
-			locStringArgs.Index = locIndices[lang]
-			locStringArgs.IsLast = locIndices[lang]+1 >= locCounts[lang]
+			locStringArgs.Index = locIndices[file]
+			locStringArgs.IsLast = locIndices[file]+1 >= locCounts[file]
 			locStringArgs.Key = key
 			locStringArgs.Lang = lang
+			locStringArgs.Namespace = file.namespace
 			locStringArgs.Value = value
 			locStringArgs.FormatArgs = formatArgs[key]
+			locStringArgs.Description = ""
+			locStringArgs.Message = nil
+			locStringArgs.MessageArgs = nil
+			locStringArgs.Messages = nil
+			locStringArgs.IsArray = false
+			locStringArgs.Items = nil
+			if m, ok := meta[key]; ok {
+				locStringArgs.Description = m.Description
+				locStringArgs.Message = m.Messages[lang]
+				locStringArgs.MessageArgs = m.MessageArgs
+				locStringArgs.Messages = m.Messages
+				locStringArgs.IsArray = m.Items != nil
+				locStringArgs.Items = m.Items[lang]
+			}
 
 			// Write a localized string
 			if value != "" {
-				localizedString := platform.LocalizedString(locStringArgs)
+				localizedString := ""
+				if w, ok := platform.(ArrayWriter); ok && locStringArgs.IsArray {
+					localizedString = w.LocalizedArray(locStringArgs)
+				} else {
+					localizedString = platform.LocalizedString(locStringArgs)
+				}
 				if _, error = buf.WriteString(localizedString); error != nil {
 					return
 				}
+				if mergeIn != nil {
+					mergeIn.keys[file] = append(mergeIn.keys[file], key)
+				}
 			} else if p, ok := platform.(FallbackStringWriter); ok {
 				fallbackString := p.FallbackString(locStringArgs)
 				if _, error = buf.WriteString(fallbackString); error != nil {
 					return
 				}
+				if mergeIn != nil {
+					mergeIn.keys[file] = append(mergeIn.keys[file], key)
+				}
 			}
-			locIndices[lang]++
+			locIndices[file]++
 		}
 	}
 
 	// Write footers
-	if error = writeFooters(platform, buffers); error != nil {
+	if error = writeFooters(platform, skeletons); error != nil {
 		return
 	}
 
 	// Write all buffers to files
-	if error = writeBuffers(platform, dir, localizations, defLocLang, defLocPath, buffers); error != nil {
-		return
-	}
-
-	return nil
+	return writeBuffers(platform, dir, defLocLang, defLocPath, buffers, mergeIn, extra)
 }
 
-func localizationFilePath(platform Platform, dir ResDir, lang Lang, defLocLang Lang, defLocPath string) (resDir string, fileName string, err error) {
+func localizationFilePath(platform Platform, dir ResDir, lang Lang, namespace Namespace, defLocLang Lang, defLocPath string) (resDir string, fileName string, err error) {
 	// Handle default language
 	if len(defLocLang) > 0 && lang == defLocLang && len(defLocPath) > 0 {
 		resDir = path.Dir(defLocPath)
 		fileName = path.Base(defLocPath)
+		if namespace != "" {
+			// Put namespaced files next to the default localization file
+			fileName = path.Base(platform.LocalizationFilePath(lang, namespace, dir))
+		}
 	} else {
-		filePath := platform.LocalizationFilePath(lang, dir)
+		filePath := platform.LocalizationFilePath(lang, namespace, dir)
 		if len(filePath) == 0 {
 			return "", "", &emptyLocalizationFilePath{}
 		}
@@ -194,13 +507,81 @@ func localizationFilePath(platform Platform, dir ResDir, lang Lang, defLocLang L
 	return
 }
 
+// KeyNamespaces returns a namespace for each localized string key depending on the split mode
+// (one of SplitByNone, SplitByTab or SplitByPrefix).
+func KeyNamespaces(localizations Localizations, meta LocalizationMeta, splitBy string, separator string) (LocalizationNamespaces, error) {
+	namespaces := LocalizationNamespaces{}
+	switch splitBy {
+	case SplitByNone, "":
+	case SplitByTab:
+		for key := range localizations {
+			if m, ok := meta[key]; ok {
+				namespaces[key] = m.Cell.Tab()
+			}
+		}
+	case SplitByPrefix:
+		if separator == "" {
+			return nil, &emptyNamespaceSeparator{}
+		}
+		for key := range localizations {
+			if i := strings.Index(key, separator); i > 0 {
+				namespaces[key] = key[:i]
+			}
+		}
+	default:
+		return nil, &unknownSplitModeError{mode: splitBy}
+	}
+	return namespaces, nil
+}
+
 // region Errors
 
 type emptyLocalizationFilePath struct {
 }
 
+type invalidOutputFilePath struct {
+	path   string
+	reason string
+}
+
+type duplicateOutputFilePath struct {
+	path string
+}
+
+type sharedOutputFilePath struct {
+	path       string
+	namespaces []Namespace
+}
+
+type emptyNamespaceSeparator struct {
+}
+
+type unknownSplitModeError struct {
+	mode string
+}
+
 func (e *emptyLocalizationFilePath) Error() string {
 	return fmt.Sprintf("empty localization file path")
 }
 
+func (e *invalidOutputFilePath) Error() string {
+	return fmt.Sprintf(`invalid output file path "%v": %v`, e.path, e.reason)
+}
+
+func (e *duplicateOutputFilePath) Error() string {
+	return fmt.Sprintf(`output file "%v" is specified more than once`, e.path)
+}
+
+func (e *sharedOutputFilePath) Error() string {
+	return fmt.Sprintf(`output file "%v" is shared by multiple namespaces (%q), platform probably doesn't support splitting localizations into multiple files`, e.path, e.namespaces)
+}
+
+func (e *emptyNamespaceSeparator) Error() string {
+	return fmt.Sprintf("namespace separator must not be empty")
+}
+
+func (e *unknownSplitModeError) Error() string {
+	return fmt.Sprintf(`unknown split mode "%v", must be one of "%v", "%v" or "%v"`, e.mode, SplitByNone, SplitByTab, SplitByPrefix)
+}
+
 // endregion
@@ -0,0 +1,221 @@
+package goloc
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratedFilePath(t *testing.T) {
+	p, err := GeneratedFilePath("res", filepath.Join("values-en", "strings.xml"))
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join("res", "values-en", "strings.xml"), p)
+
+	p, err = GeneratedFilePath("res", filepath.Join("a", "..", "b.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join("res", "b.json"), p)
+
+	badPaths := []string{
+		"",
+		filepath.Join("..", "strings.xml"),
+		filepath.Join("a", "..", "..", "strings.xml"),
+		"..",
+		string(filepath.Separator) + "strings.xml",
+	}
+	for _, bad := range badPaths {
+		_, err := GeneratedFilePath("res", bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestKeyNamespaces(t *testing.T) {
+	loc := Localizations{
+		"onboarding.title": {"en": "Welcome"},
+		"checkout.pay":     {"en": "Pay"},
+		"ok":               {"en": "OK"},
+		".hidden":          {"en": "Hidden"},
+	}
+	meta := LocalizationMeta{
+		"onboarding.title": {Cell: *NewCell("onboarding", 2, 0)},
+		"checkout.pay":     {Cell: *NewCell("checkout", 2, 0)},
+		"ok":               {Cell: *NewCell("checkout", 3, 0)},
+		".hidden":          {Cell: *NewCell("checkout", 4, 0)},
+	}
+
+	ns, err := KeyNamespaces(loc, meta, SplitByNone, ".")
+	assert.Nil(t, err)
+	assert.Empty(t, ns)
+
+	ns, err = KeyNamespaces(loc, meta, SplitByTab, ".")
+	assert.Nil(t, err)
+	assert.Equal(t, LocalizationNamespaces{"onboarding.title": "onboarding", "checkout.pay": "checkout", "ok": "checkout", ".hidden": "checkout"}, ns)
+
+	ns, err = KeyNamespaces(loc, meta, SplitByPrefix, ".")
+	assert.Nil(t, err)
+	assert.Equal(t, LocalizationNamespaces{"onboarding.title": "onboarding", "checkout.pay": "checkout"}, ns)
+
+	_, err = KeyNamespaces(loc, meta, SplitByPrefix, "")
+	assert.IsType(t, &emptyNamespaceSeparator{}, err)
+
+	_, err = KeyNamespaces(loc, meta, "unknown", ".")
+	assert.IsType(t, &unknownSplitModeError{}, err)
+}
+
+func TestWriteFilesSkipsUnchanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	en := filepath.Join(dir, "en", "strings.json")
+	ru := filepath.Join(dir, "ru", "strings.json")
+
+	summary, err := writeFiles(map[string]*bytes.Buffer{
+		en: bytes.NewBufferString(`{"title": "Title"}`),
+		ru: bytes.NewBufferString(`{"title": "Заголовок"}`),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{en, ru}, summary.Written)
+	assert.Empty(t, summary.Unchanged)
+
+	summary, err = writeFiles(map[string]*bytes.Buffer{
+		en: bytes.NewBufferString(`{"title": "Title"}`),
+		ru: bytes.NewBufferString(`{"title": "Новый заголовок"}`),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ru}, summary.Written)
+	assert.Equal(t, []string{en}, summary.Unchanged)
+	assert.Equal(t, "1 written, 1 unchanged, 0 removed", summary.String())
+
+	contents, err := ioutil.ReadFile(ru)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"title": "Новый заголовок"}`, string(contents))
+}
+
+func TestWriteFilesFailureLeavesFilesIntact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	en := filepath.Join(dir, "en.json")
+	assert.Nil(t, ioutil.WriteFile(en, []byte(`{"title": "Title"}`), 0644))
+
+	// A regular file in place of the directory makes writing the "ru" file fail
+	blocker := filepath.Join(dir, "ru")
+	assert.Nil(t, ioutil.WriteFile(blocker, nil, 0644))
+
+	_, err = writeFiles(map[string]*bytes.Buffer{
+		en:                             bytes.NewBufferString(`{"title": "New title"}`),
+		filepath.Join(blocker, "json"): bytes.NewBufferString(`{"title": "Заголовок"}`),
+	})
+	assert.Error(t, err)
+
+	contents, err := ioutil.ReadFile(en)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"title": "Title"}`, string(contents))
+
+	entries, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"en.json", "ru"}, names)
+}
+
+func TestCommitStagedFilesRollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	existing := filepath.Join(dir, "existing.json")
+	assert.Nil(t, ioutil.WriteFile(existing, []byte(`old`), 0644))
+	created := filepath.Join(dir, "created.json")
+
+	var staged []*stagedFile
+	for _, p := range []string{existing, created} {
+		f, err := stageFile(p, []byte(`new`))
+		assert.Nil(t, err)
+		staged = append(staged, f)
+	}
+	// The last file can't be committed since its temporary file is gone
+	staged = append(staged, &stagedFile{path: filepath.Join(dir, "broken.json"), tempPath: filepath.Join(dir, "missing.tmp")})
+
+	assert.Error(t, commitStagedFiles(staged))
+
+	contents, err := ioutil.ReadFile(existing)
+	assert.Nil(t, err)
+	assert.Equal(t, `old`, string(contents))
+	assert.NoFileExists(t, created)
+
+	entries, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
+
+type failingMockPlatform struct {
+	*mockPlatform
+}
+
+func (failingMockPlatform) LocalizationFilePath(lang Lang, namespace Namespace, resDir ResDir) string {
+	return filepath.Join(resDir, lang+".json")
+}
+
+func (failingMockPlatform) LocalizedString(args *LocalizedStringArgs) string {
+	return ""
+}
+
+func (failingMockPlatform) Err() error {
+	return errors.New("can't render template")
+}
+
+func TestWriteLocalizationsReportedErrorLeavesFilesIntact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	en := filepath.Join(dir, "en.json")
+	assert.Nil(t, ioutil.WriteFile(en, []byte(`{"title": "Title"}`), 0644))
+
+	_, err = WriteLocalizations(failingMockPlatform{newMockPlatform(nil)}, dir, Localizations{"title": {"en": "New title"}}, nil, nil, nil, "", "", "", false, nil)
+	assert.EqualError(t, err, "can't render template")
+
+	contents, err := ioutil.ReadFile(en)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"title": "Title"}`, string(contents))
+}
+
+func TestWriteLocalizationsExtraFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	platform := mergerMockPlatform{newMockPlatform(nil)}
+	en := filepath.Join(dir, "values-en", "strings.xml")
+	plurals := filepath.Join(dir, "values-en", "plurals.xml")
+	extra := []OutputFile{{Path: plurals, Contents: "plurals"}}
+
+	loc := Localizations{"title": {"en": "Title"}}
+	summary, err := WriteLocalizations(platform, dir, loc, nil, nil, nil, "", "", "", true, extra)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{plurals, en}, summary.Written)
+	// Extra files are generated as a whole even when merging
+	assert.Equal(t, []string{en}, summary.Merged)
+	_, err = updateManifest(dir, summary, false)
+	assert.Nil(t, err)
+
+	m, err := readManifest(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"values-en/plurals.xml"}, m.Files)
+
+	// Extra files are written only along with the localization files
+	_, err = WriteLocalizations(platform, dir, loc, nil, nil, nil, "", "", "", false, []OutputFile{{Path: plurals, Contents: "changed"}, {Path: en}})
+	assert.IsType(t, &duplicateOutputFilePath{}, err)
+	contents, err := ioutil.ReadFile(plurals)
+	assert.Nil(t, err)
+	assert.Equal(t, "plurals", string(contents))
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/s0nerik/goloc/goloc"
 	"github.com/s0nerik/goloc/registry"
@@ -13,112 +17,258 @@ import (
 	_ "github.com/s0nerik/goloc/platforms"
 )
 
-const remoteSources = `google_sheets`
-const localSources = `csv`
-
 // Must be set using '-ldflags "-X main.version=<version>"'
 var version string
 
-var availableSources = fmt.Sprintf(`%v, %v`, remoteSources, localSources)
+var (
+	generateCmd = kingpin.Command(`generate`, `Generate localization files (default).`).Default()
+
+	platformsCmd     = kingpin.Command(`platforms`, `List registered platforms or describe a specific one.`)
+	platformsCmdName = platformsCmd.Arg(`name`, `Platform name.`).String()
+	platformsCmdJSON = platformsCmd.Flag(`json`, `Print the output in JSON format.`).Bool()
+
+	sourcesCmd     = kingpin.Command(`sources`, `List registered sources or describe a specific one.`)
+	sourcesCmdName = sourcesCmd.Arg(`name`, `Source name.`).String()
+	sourcesCmdJSON = sourcesCmd.Flag(`json`, `Print the output in JSON format.`).Bool()
+
+	checkCmd      = kingpin.Command(`check`, `Check whether the source data changed since the last generation recorded in the lock file.`)
+	checkSource   = sourceFlags(checkCmd)
+	checkLockFile = checkCmd.Flag(`lock-file`, `Path to the lock file.`).Default(`goloc.lock`).String()
+)
 
 var (
 	// Basic params
-	source       = kingpin.Flag(`source`, fmt.Sprintf(`Data source. Available sources: %v`, availableSources)).Default(`google_sheets`).String()
-	platformName = kingpin.Flag(`platform`, `Target platform name.`).Short('p').Required().String()
-	resDir       = kingpin.Flag(`resources`, `Path to the resources folder in the project.`).Short('r').Required().String()
+	platformName = generateCmd.Flag(`platform`, `Target platform name. Custom platforms can be specified as "template:<config file path>".`).Short('p').Required().String()
+	resDir       = generateCmd.Flag(`resources`, `Path to the resources folder in the project.`).Short('r').Required().String()
 
-	// Local source params
-	locFilePath     = kingpin.Flag(`localizations-file-path`, fmt.Sprintf(`Localizations file path. Required for sources: %v`, localSources)).String()
-	formatsFilePath = kingpin.Flag(`formats-file-path`, fmt.Sprintf(`Formats file path. Required for sources: %v`, localSources)).String()
+	// Source and its params (declared by the registered sources)
+	generateSource = sourceFlags(generateCmd)
 
-	// Google Sheets params
-	sheetID        = kingpin.Flag(`spreadsheet`, `Spreadsheet ID. Required if selected source is 'google_sheets'`).Short('s').String()
-	credentials    = kingpin.Flag(`credentials`, `Credentials to access a spreadsheet.`).Short('c').Default(`client_secret.json`).String()
-	tabName        = kingpin.Flag(`tab`, `Localizations tab name.`).Short('t').Default(`localizations`).String()
-	formatsTabName = kingpin.Flag(`formats-tab`, `Formats tab name.`).Short('f').Default(`formats`).String()
+	// Params of the registered platforms
+	generatePlatformParams = platformFlags(generateCmd)
+
+	// Lock file
+	lockFile = generateCmd.Flag(`lock-file`, `Path to the lock file recording the source data used for the generation (e.g. "goloc.lock"). Not written if not specified.`).String()
 
 	// Advanced configuration
-	keyColumn              = kingpin.Flag(`key-column`, `Title of the key column.`).Default(`key`).String()
-	stopOnMissing          = kingpin.Flag(`stop-on-missing`, `Stop execution if missing localization is found.`).Default(`false`).Bool()
-	formatNameColumn       = kingpin.Flag(`format-name-column`, `Title of the format name column.`).Default(`format`).String()
-	defFormatName          = kingpin.Flag(`default-format-name`, `Name of the format to be used in place of "{}"`).Default("").String()
-	defLoc                 = kingpin.Flag(`default-localization`, `Default localization language (e.g. "en"). Specifying this doesn't have any effect if the "--default-localization-file-path" is not specified.`).Default(`en`).String()
-	defLocPath             = kingpin.Flag(`default-localization-file-path`, `Full path to the default localization file. Specify this if you want to write a default localization into a specific file (ignoring the localization path generation logic for a language specified in "--default-localization").`).String()
-	emptyLocalizationMatch = kingpin.Flag(`empty-localization-match`, `Regex for empty localization string.`).Default(`^$`).Regexp()
+	keyColumn              = generateCmd.Flag(`key-column`, `Title of the key column.`).Default(`key`).String()
+	descriptionColumn      = generateCmd.Flag(`description-column`, `Title of the optional description column.`).Default(`description`).String()
+	platformsColumn        = generateCmd.Flag(`platforms-column`, `Title of the optional column listing platforms a row is targeted at (e.g. "android,ios" or "!web").`).Default(`platforms`).String()
+	keyCase                = generateCmd.Flag(`key-case`, fmt.Sprintf(`Convert keys into the given case: "%v" (default), "%v" or "%v". Parts of the key separated by "." are converted separately.`, goloc.KeyCaseNone, goloc.KeyCaseSnake, goloc.KeyCaseCamel)).Default(goloc.KeyCaseNone).Enum(goloc.KeyCaseNone, goloc.KeyCaseSnake, goloc.KeyCaseCamel)
+	onDuplicate            = generateCmd.Flag(`on-duplicate`, fmt.Sprintf(`What to do with keys defined more than once: "%v" (default), "%v" (report and use the first definition), "%v" or "%v" (silently use the first or the last definition).`, goloc.OnDuplicateError, goloc.OnDuplicateWarn, goloc.OnDuplicateFirst, goloc.OnDuplicateLast)).Default(goloc.OnDuplicateError).Enum(goloc.OnDuplicateError, goloc.OnDuplicateWarn, goloc.OnDuplicateFirst, goloc.OnDuplicateLast)
+	ignoreKeyCase          = generateCmd.Flag(`ignore-key-case`, `Treat keys which differ only in case as duplicates.`).Default(`false`).Bool()
+	stopOnMissing          = generateCmd.Flag(`stop-on-missing`, `Stop execution if missing localization is found.`).Default(`false`).Bool()
+	formatNameColumn       = generateCmd.Flag(`format-name-column`, `Title of the format name column.`).Default(`format`).String()
+	defFormatName          = generateCmd.Flag(`default-format-name`, `Name of the format to be used in place of "{}"`).Default("").String()
+	defLoc                 = generateCmd.Flag(`default-localization`, `Default localization language (e.g. "en"). Specifying this doesn't have any effect if the "--default-localization-file-path" is not specified.`).Default(`en`).String()
+	defLocPath             = generateCmd.Flag(`default-localization-file-path`, `Full path to the default localization file. Specify this if you want to write a default localization into a specific file (ignoring the localization path generation logic for a language specified in "--default-localization").`).String()
+	emptyLocalizationMatch = generateCmd.Flag(`empty-localization-match`, `Regex for empty localization string.`).Default(`^$`).Regexp()
+
+	// Output splitting
+	splitBy            = generateCmd.Flag(`split-by`, fmt.Sprintf(`Split localized strings of each language into multiple files: "%v" (default), "%v" (file per localizations tab) or "%v" (file per key prefix).`, goloc.SplitByNone, goloc.SplitByTab, goloc.SplitByPrefix)).Default(goloc.SplitByNone).Enum(goloc.SplitByNone, goloc.SplitByTab, goloc.SplitByPrefix)
+	namespaceSeparator = generateCmd.Flag(`namespace-separator`, fmt.Sprintf(`Separator between the key prefix and the rest of the key. Used with "--split-by=%v".`, goloc.SplitByPrefix)).Default(`.`).String()
+
+	// Generated files
+	prune = generateCmd.Flag(`prune`, fmt.Sprintf(`Remove previously generated files which aren't produced anymore (e.g. for a removed language). Only files listed in "%v" in the resources folder are removed.`, goloc.ManifestFileName)).Default(`false`).Bool()
+
+	merge = generateCmd.Flag(`merge`, `Merge localized strings into the existing files instead of overwriting them. Hand-written entries outside of the region generated by goloc are preserved.`).Default(`false`).Bool()
 
 	// Extra features
-	missingLocalizationsReport = kingpin.Flag(`missing-localizations-report`, `Specify this flag if you want to only pretty-print missing localizations without generating the actual localization files.`).Default(`false`).Bool()
+	missingLocalizationsReport = generateCmd.Flag(`missing-localizations-report`, `Specify this flag if you want to only pretty-print missing localizations without generating the actual localization files.`).Default(`false`).Bool()
 )
 
 func main() {
 	kingpin.Version(version)
-	kingpin.Parse()
 
-	platform := registry.GetPlatform(*platformName)
-	if platform == nil {
-		log.Fatalf(`Platform "%v" is not supported.`, *platformName)
+	var err error
+	switch kingpin.Parse() {
+	case platformsCmd.FullCommand():
+		err = describePlatforms(os.Stdout, *platformsCmdName, *platformsCmdJSON)
+	case sourcesCmd.FullCommand():
+		err = describeSources(os.Stdout, *sourcesCmdName, *sourcesCmdJSON)
+	case checkCmd.FullCommand():
+		err = check()
+	default:
+		err = generate()
 	}
 
-	src := resolveSource()
-	if src == nil {
-		log.Fatalf(`"%v" is not a supported source. Supported sources: %v.`, *source, availableSources)
+	if err != nil {
+		log.Fatal(err)
 	}
+}
 
-	err := goloc.Run(
-		src,
-		platform,
-		*resDir,
-		*keyColumn,
-		*formatNameColumn,
-		*defLoc,
-		*defLocPath,
-		*stopOnMissing,
-		*missingLocalizationsReport,
-		*defFormatName,
-		*emptyLocalizationMatch,
-	)
+func generate() error {
+	params := registry.PlatformParams{}
+	for name, value := range generatePlatformParams {
+		params[name] = *value
+	}
+	platform, err := registry.ResolvePlatform(*platformName, params)
+	if err != nil {
+		return err
+	}
+	if p, ok := platform.(registry.ConfigurablePlatform); ok {
+		if err := p.Configure(params); err != nil {
+			return fmt.Errorf(`can't configure "%v" platform: %w`, *platformName, err)
+		}
+	}
 
+	src, err := generateSource.resolve()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+
+	return goloc.Run(src, platform, goloc.RunOptions{
+		ResDir:                     *resDir,
+		KeyColumn:                  *keyColumn,
+		DescriptionColumn:          *descriptionColumn,
+		PlatformsColumn:            *platformsColumn,
+		KnownPlatforms:             registry.PlatformNames(),
+		KeyCase:                    *keyCase,
+		OnDuplicate:                *onDuplicate,
+		IgnoreKeyCase:              *ignoreKeyCase,
+		FormatNameColumn:           *formatNameColumn,
+		DefaultFormatName:          *defFormatName,
+		DefaultLocalization:        *defLoc,
+		DefaultLocalizationPath:    *defLocPath,
+		StopOnMissing:              *stopOnMissing,
+		ReportMissingLocalizations: *missingLocalizationsReport,
+		EmptyLocalizationMatch:     *emptyLocalizationMatch,
+		SplitBy:                    *splitBy,
+		NamespaceSeparator:         *namespaceSeparator,
+		LockFilePath:               *lockFile,
+		Version:                    version,
+		Prune:                      *prune,
+		Merge:                      *merge,
+	})
 }
 
-func resolveSource() goloc.Source {
-	switch *source {
-	case "google_sheets":
-		if sheetID == nil {
-			log.Fatalf(`"--spreadsheet" parameter must be specified`)
-		}
-		if credentials == nil {
-			log.Fatalf(`"--credentials" parameter must be specified`)
+func check() error {
+	src, err := checkSource.resolve()
+	if err != nil {
+		return err
+	}
+
+	if err := goloc.Check(src, *checkLockFile); err != nil {
+		return err
+	}
+
+	fmt.Println(`Source data didn't change since the last generation.`)
+	return nil
+}
+
+func availableSources() string {
+	var names []string
+	for _, f := range registry.Sources() {
+		names = append(names, fmt.Sprintf(`%v (%v)`, f.Names()[0], strings.TrimSuffix(f.Description(), `.`)))
+	}
+	return strings.Join(names, `, `)
+}
+
+// platformFlags declares a flag for each parameter of the registered platforms and platform factories.
+func platformFlags(cmd *kingpin.CmdClause) map[string]*string {
+	params := map[string]*string{}
+	declare := func(param registry.PlatformParam, usedBy string) {
+		flag := cmd.Flag(param.Name, fmt.Sprintf(`%v Used by platform: %v`, param.Description, usedBy))
+		if param.Default != `` {
+			flag = flag.Default(param.Default)
 		}
-		if *tabName == "" {
-			log.Fatalf(`"--tab" parameter cannot be empty`)
+		params[param.Name] = flag.String()
+	}
+
+	for _, p := range registry.Platforms() {
+		if c, ok := p.(registry.ConfigurablePlatform); ok {
+			for _, param := range c.Params() {
+				declare(param, p.Names()[0])
+			}
 		}
-		if *formatsTabName == "" {
-			log.Fatalf(`"--formats-tab" parameter cannot be empty`)
+	}
+	for _, prefix := range registry.PlatformFactoryPrefixes() {
+		for _, param := range registry.PlatformFactoryParams(prefix) {
+			declare(param, prefix+`:<argument>`)
 		}
+	}
+	return params
+}
+
+// sourceOptions holds the flags specifying a data source.
+type sourceOptions struct {
+	name     *string
+	params   map[string]func() string
+	cacheDir *string
+	offline  *bool
+}
 
-		source, err := sources.GoogleSheets(*credentials, *sheetID, *formatsTabName, *tabName)
-		if err != nil {
-			log.Fatalf("can't create googlesheets source, %v", err.Error())
+// sourceFlags declares the source flags, including a flag for each parameter of the registered sources.
+func sourceFlags(cmd *kingpin.CmdClause) *sourceOptions {
+	opts := &sourceOptions{
+		name:   cmd.Flag(`source`, fmt.Sprintf(`Data source. Available sources: %v`, availableSources())).Default(`google_sheets`).String(),
+		params: map[string]func() string{},
+	}
+
+	usedBy := map[string][]string{}
+	descriptions := map[string]*registry.SourceParam{}
+
+	var order []string
+	for _, f := range registry.Sources() {
+		for _, p := range f.Params() {
+			p := p
+			if _, ok := descriptions[p.Name]; !ok {
+				descriptions[p.Name] = &p
+				order = append(order, p.Name)
+			}
+			usedBy[p.Name] = append(usedBy[p.Name], f.Names()[0])
 		}
+	}
 
-		return source
-	case "csv":
-		if locFilePath == nil {
-			log.Fatalf(`"--localizations-file-path" parameter must be specified`)
+	for _, name := range order {
+		p := descriptions[name]
+		flag := cmd.Flag(name, fmt.Sprintf(`%v Used by sources: %v`, p.Description, strings.Join(usedBy[name], `, `)))
+		if p.Short != 0 {
+			flag = flag.Short(p.Short)
 		}
-		if *locFilePath == "" {
-			log.Fatalf(`"--localizations-file-path" must be a valid file path`)
+		if p.Default != `` {
+			flag = flag.Default(p.Default)
 		}
-		if formatsFilePath == nil {
-			log.Fatalf(`"--formats-file-path" parameter must be specified`)
+		if p.Bool {
+			value := flag.Bool()
+			opts.params[name] = func() string { return strconv.FormatBool(*value) }
+		} else {
+			value := flag.String()
+			opts.params[name] = func() string { return *value }
 		}
-		if *formatsFilePath == "" {
-			log.Fatalf(`"--formats-file-path" must be a valid file path`)
+	}
+
+	opts.cacheDir = cmd.Flag(`cache-dir`, `Directory to store a snapshot of the fetched source data in. If specified, the snapshot is used when the source can't be reached due to a network failure.`).String()
+	opts.offline = cmd.Flag(`offline`, `Use the snapshot stored in "--cache-dir" instead of fetching the source.`).Default(`false`).Bool()
+
+	return opts
+}
+
+// values returns the specified source parameters.
+func (o *sourceOptions) values() registry.SourceParams {
+	params := registry.SourceParams{}
+	for name, value := range o.params {
+		params[name] = value()
+	}
+	return params
+}
+
+func (o *sourceOptions) resolve() (goloc.Source, error) {
+	if *o.offline {
+		if *o.cacheDir == `` {
+			return nil, errors.New(`"--offline" requires "--cache-dir" to be specified`)
 		}
-		return sources.CSV(*locFilePath, *formatsFilePath)
+		return sources.Snapshot(*o.cacheDir, *o.name, registry.SourceID(*o.name, o.values())), nil
 	}
-	return nil
+
+	params := o.values()
+	src, err := registry.ResolveSource(*o.name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if *o.cacheDir != `` {
+		return sources.Cached(*o.cacheDir, *o.name, src), nil
+	}
+	return src, nil
 }
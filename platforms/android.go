@@ -1,11 +1,16 @@
 package platforms
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/icu"
 	"github.com/s0nerik/goloc/registry"
 )
 
@@ -13,7 +18,34 @@ func init() {
 	registry.RegisterPlatform(&android{})
 }
 
-type android struct{}
+const (
+	androidRegionBegin = "<!-- goloc:begin (generated strings, do not edit) -->"
+	androidRegionEnd   = "<!-- goloc:end -->"
+)
+
+var androidKeyRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+var javaReservedWords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true, "catch": true,
+	"char": true, "class": true, "const": true, "continue": true, "default": true, "do": true, "double": true,
+	"else": true, "enum": true, "extends": true, "false": true, "final": true, "finally": true, "float": true,
+	"for": true, "goto": true, "if": true, "implements": true, "import": true, "instanceof": true, "int": true,
+	"interface": true, "long": true, "native": true, "new": true, "null": true, "package": true, "private": true,
+	"protected": true, "public": true, "return": true, "short": true, "static": true, "strictfp": true,
+	"super": true, "switch": true, "synchronized": true, "this": true, "throw": true, "throws": true,
+	"transient": true, "true": true, "try": true, "void": true, "volatile": true, "while": true,
+}
+
+var androidArgNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var androidResourceNameRegexp = regexp.MustCompile(`<(?:string|plurals|string-array)\b[^>]*\bname\s*=\s*"([^"]*)"`)
+
+// android generates string resources. Kotlin helpers for the ICU messages are written into messagesFile if it's
+// configured (see ExtraFiles).
+type android struct {
+	messagesPackage string
+	messagesFile    string
+}
 
 func (android) Names() []string {
 	return []string{
@@ -22,8 +54,11 @@ func (android) Names() []string {
 	}
 }
 
-func (android) LocalizationFilePath(lang goloc.Lang, resDir goloc.ResDir) string {
+func (android) LocalizationFilePath(lang goloc.Lang, namespace goloc.Namespace, resDir goloc.ResDir) string {
 	fileName := "localized_strings.xml"
+	if namespace != "" {
+		fileName = fmt.Sprintf("strings_%v.xml", androidResourceName(namespace))
+	}
 	targetDir := fmt.Sprintf("values-%v", lang)
 	if resDir != "" {
 		return filepath.Join(resDir, targetDir, fileName)
@@ -36,13 +71,75 @@ func (android) Header(args *goloc.HeaderArgs) string {
 }
 
 func (android) LocalizedString(args *goloc.LocalizedStringArgs) string {
-	return fmt.Sprintf("\t<string name=\"%v\">%v</string>\n", args.Key, args.Value)
+	str := fmt.Sprintf("\t<string name=\"%v\">%v</string>\n", args.Key, args.Value)
+	if args.Message != nil {
+		str = androidMessageResources(args)
+	}
+	if args.Description != "" {
+		return fmt.Sprintf("\t<!-- %v -->\n%v", androidComment(args.Description), str)
+	}
+	return str
+}
+
+func (android) LocalizedArray(args *goloc.LocalizedStringArgs) string {
+	var b strings.Builder
+	if args.Description != "" {
+		fmt.Fprintf(&b, "\t<!-- %v -->\n", androidComment(args.Description))
+	}
+	fmt.Fprintf(&b, "\t<string-array name=\"%v\">\n", args.Key)
+	for _, item := range args.Items {
+		fmt.Fprintf(&b, "\t\t<item>%v</item>\n", item)
+	}
+	b.WriteString("\t</string-array>\n")
+	return b.String()
 }
 
 func (android) Footer(args *goloc.FooterArgs) string {
 	return "</resources>\n"
 }
 
+// Merge places generated strings into a marked region within the <resources> element. Existing region is replaced,
+// otherwise a new one is inserted before the closing </resources> tag.
+func (android) Merge(args *goloc.MergeArgs) (merged string, conflicts []goloc.Key, err error) {
+	region := fmt.Sprintf("\t%v\n%v\t%v\n", androidRegionBegin, args.Strings, androidRegionEnd)
+
+	merged, ok := goloc.ReplaceRegion(args.Existing, androidRegionBegin, androidRegionEnd, region)
+	if !ok {
+		i := strings.LastIndex(args.Existing, "</resources>")
+		if i < 0 {
+			return "", nil, errors.New(`no closing </resources> tag found`)
+		}
+		if lineStart := strings.LastIndex(args.Existing[:i], "\n") + 1; strings.TrimSpace(args.Existing[lineStart:i]) == "" {
+			merged = args.Existing[:lineStart] + region + args.Existing[lineStart:]
+		} else {
+			merged = args.Existing[:i] + "\n" + region + args.Existing[i:]
+		}
+	}
+
+	manualNames := map[string]bool{}
+	for _, m := range androidResourceNameRegexp.FindAllStringSubmatch(goloc.OutsideRegion(merged, androidRegionBegin, androidRegionEnd), -1) {
+		manualNames[m[1]] = true
+	}
+	for _, key := range args.Keys {
+		if manualNames[key] {
+			conflicts = append(conflicts, key)
+		}
+	}
+	return
+}
+
+// ValidateKey requires keys to be valid resource names, which become fields of the generated R.string class
+// (with "." replaced by "_").
+func (android) ValidateKey(key goloc.Key) error {
+	if !androidKeyRegexp.MatchString(key) {
+		return errors.New(`must start with a letter or "_" and contain only letters, digits, "_" and "."`)
+	}
+	if field := strings.ReplaceAll(key, ".", "_"); javaReservedWords[field] {
+		return fmt.Errorf(`"%s" is a reserved word in Java`, field)
+	}
+	return nil
+}
+
 func (android) ValidateFormat(format string) error {
 	return nil
 }
@@ -51,6 +148,175 @@ func (android) FormatString(args *goloc.FormatStringArgs) string {
 	return fmt.Sprintf(`%%%v$%v`, args.Index+1, strings.TrimPrefix(args.Format, `%`))
 }
 
+func (android) InlineFormats() goloc.Formats {
+	return goloc.Formats{
+		goloc.InlineFormatString:   `s`,
+		goloc.InlineFormatNumber:   `d`,
+		goloc.InlineFormatInteger:  `d`,
+		goloc.InlineFormatDecimal:  `f`,
+		goloc.InlineFormatCurrency: `.2f`,
+	}
+}
+
+// ValidateMessage accepts messages with select arguments and at most one plural argument without an offset and explicit
+// values. Each combination of the select cases becomes a separate resource which is chosen by a generated Kotlin
+// helper (see ExtraFiles), so it must contain at most one plural argument.
+func (android) ValidateMessage(message icu.Message) error {
+	plurals := 0
+	for _, arg := range message.Args() {
+		if !androidArgNameRegexp.MatchString(arg.Name) || javaReservedWords[arg.Name] {
+			return fmt.Errorf(`"%v" argument: name must be a valid Kotlin identifier`, arg.Name)
+		}
+		if arg.Type == icu.TypePlural {
+			plurals++
+		}
+	}
+	if plurals > 1 {
+		return errors.New(`only one plural argument is supported`)
+	}
+	return validateAndroidMessage(message, false)
+}
+
+func validateAndroidMessage(message icu.Message, inPlural bool) error {
+	hasPlural := false
+	for _, n := range message {
+		s, ok := n.(*icu.Select)
+		if !ok {
+			continue
+		}
+		switch {
+		case s.Type == icu.TypeSelectOrdinal:
+			return fmt.Errorf(`"%v" argument: %v arguments aren't supported`, s.Name, s.Type)
+		case s.Type == icu.TypePlural && (inPlural || hasPlural):
+			return fmt.Errorf(`"%v" argument: only one plural argument is supported in each select case`, s.Name)
+		case s.Type == icu.TypePlural && s.Offset != 0:
+			return fmt.Errorf(`"%v" argument: offset isn't supported`, s.Name)
+		}
+		for _, c := range s.Cases {
+			if strings.HasPrefix(c.Key, "=") {
+				return fmt.Errorf(`"%v" argument: "%v" case isn't supported`, s.Name, c.Key)
+			}
+			if err := validateAndroidMessage(c.Message, inPlural || s.Type == icu.TypePlural); err != nil {
+				return err
+			}
+		}
+		hasPlural = hasPlural || s.Type == icu.TypePlural
+	}
+	return nil
+}
+
+func (android) Params() []registry.PlatformParam {
+	return []registry.PlatformParam{
+		{Name: "android-messages-package", Description: `Package of the Kotlin helpers generated for the ICU messages. Required along with "--android-messages-file".`},
+		{Name: "android-messages-file", Description: `Path to the Kotlin helpers generated for the ICU messages (e.g. "app/src/main/java/com/example/LocalizedMessages.kt"). The helpers aren't generated unless it's specified.`},
+	}
+}
+
+func (p *android) Configure(params registry.PlatformParams) error {
+	switch {
+	case params["android-messages-file"] == "" && params["android-messages-package"] != "":
+		return errors.New(`"--android-messages-file" must be specified along with "--android-messages-package"`)
+	case params["android-messages-file"] != "" && params["android-messages-package"] == "":
+		return errors.New(`"--android-messages-package" must be specified along with "--android-messages-file"`)
+	}
+	if params["android-messages-package"] != "" {
+		for _, part := range strings.Split(params["android-messages-package"], ".") {
+			if !androidArgNameRegexp.MatchString(part) || javaReservedWords[part] {
+				return fmt.Errorf(`"%v" is not a valid package name`, params["android-messages-package"])
+			}
+		}
+	}
+	p.messagesPackage = params["android-messages-package"]
+	p.messagesFile = params["android-messages-file"]
+	return nil
+}
+
+// ExtraFiles returns Kotlin helpers choosing the resource of an ICU message by the values of its arguments if the
+// helpers file is configured. The file is written even if there are no ICU messages, so that it never refers to
+// the removed resources.
+func (p android) ExtraFiles(args goloc.GenerateArgs) ([]goloc.OutputFile, error) {
+	if p.messagesFile == "" {
+		return nil, nil
+	}
+
+	var keys []goloc.Key
+	for key, m := range args.Meta {
+		if m.Messages != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `// DO NOT EDIT. This is code generated via https://github.com/s0nerik/goloc
+
+@file:Suppress("FunctionName", "unused")
+
+package %v
+`, p.messagesPackage)
+	if len(keys) == 0 {
+		return []goloc.OutputFile{{Path: p.messagesFile, Contents: b.String()}}, nil
+	}
+	b.WriteString(`
+import android.annotation.SuppressLint
+import android.content.Context
+
+@SuppressLint("DiscouragedApi")
+private fun Context.golocString(name: String, vararg args: Any): String =
+    getString(resources.getIdentifier(name, "string", packageName), *args)
+
+@SuppressLint("DiscouragedApi")
+private fun Context.golocPlurals(name: String, quantity: Int, vararg args: Any): String =
+    resources.getQuantityString(resources.getIdentifier(name, "plurals", packageName), quantity, *args)
+`)
+	for _, key := range keys {
+		m := args.Meta[key]
+		selects, cases := androidMessageSelects(m.MessageArgs, m.Messages)
+		quantity := androidMessageQuantity(m.MessageArgs)
+
+		var params, values []string
+		for _, arg := range m.MessageArgs {
+			switch arg.Type {
+			case icu.TypeSelect:
+				params = append(params, fmt.Sprintf("%v: String", arg.Name))
+			case icu.TypePlural:
+				params = append(params, fmt.Sprintf("%v: Int", arg.Name))
+			default:
+				params = append(params, fmt.Sprintf("%v: Any", arg.Name))
+			}
+			values = append(values, arg.Name)
+		}
+
+		var expr func(selection []string, indent string) string
+		expr = func(selection []string, indent string) string {
+			if len(selection) == len(selects) {
+				name := androidMessageResourceName(key, selection)
+				if quantity != "" {
+					return fmt.Sprintf(`golocPlurals("%v", %v, %v)`, name, quantity, strings.Join(values, ", "))
+				}
+				return fmt.Sprintf(`golocString("%v", %v)`, name, strings.Join(values, ", "))
+			}
+			i := len(selection)
+			var w strings.Builder
+			fmt.Fprintf(&w, "when (%v) {\n", selects[i])
+			for _, c := range cases[i] {
+				value := expr(append(selection[:i:i], c), indent+"    ")
+				if c == icu.OtherCase {
+					fmt.Fprintf(&w, "%v    else -> %v\n", indent, value)
+				} else {
+					fmt.Fprintf(&w, "%v    %v -> %v\n", indent, strconv.Quote(c), value)
+				}
+			}
+			fmt.Fprintf(&w, "%v}", indent)
+			return w.String()
+		}
+
+		fmt.Fprintf(&b, "\nfun Context.%v(%v): String = %v\n", androidFieldName(key), strings.Join(params, ", "), expr(nil, ""))
+	}
+
+	return []goloc.OutputFile{{Path: p.messagesFile, Contents: b.String()}}, nil
+}
+
 func (android) ReplacementChars() map[string]string {
 	return map[string]string{
 		`\`:  `\\`,
@@ -64,3 +330,205 @@ func (android) ReplacementChars() map[string]string {
 		`&`:  `&amp;`,
 	}
 }
+
+// androidComment makes a text safe to be put into a single-line XML comment.
+func androidComment(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for strings.Contains(text, "--") {
+		text = strings.ReplaceAll(text, "--", "- -")
+	}
+	return text
+}
+
+// androidResourceName converts a string into a valid resource file name (only lowercase a-z, 0-9 and "_" are allowed).
+func androidResourceName(str string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(str))
+}
+
+// androidMessageResources returns the resources of an ICU message: a <string> or <plurals> resource for each
+// combination of the select cases (see androidMessageSelects).
+func androidMessageResources(args *goloc.LocalizedStringArgs) string {
+	selects, cases := androidMessageSelects(args.MessageArgs, args.Messages)
+	quantity := androidMessageQuantity(args.MessageArgs)
+	w := &androidMessageWriter{args: args.MessageArgs}
+
+	var b strings.Builder
+	var write func(selection []string)
+	write = func(selection []string) {
+		if len(selection) < len(selects) {
+			i := len(selection)
+			for _, c := range cases[i] {
+				write(append(selection[:i:i], c))
+			}
+			return
+		}
+
+		chosen := map[string]string{}
+		for i, name := range selects {
+			chosen[name] = selection[i]
+		}
+		message := androidResolveSelects(args.Message, chosen)
+		name := androidMessageResourceName(args.Key, selection)
+
+		if quantity == "" {
+			fmt.Fprintf(&b, "\t<string name=\"%v\">%v</string>\n", name, w.format(message, 0))
+			return
+		}
+
+		fmt.Fprintf(&b, "\t<plurals name=\"%v\">\n", name)
+		pluralIndex := -1
+		for i, n := range message {
+			if s, ok := n.(*icu.Select); ok && s.Type == icu.TypePlural {
+				pluralIndex = i
+			}
+		}
+		if pluralIndex < 0 {
+			fmt.Fprintf(&b, "\t\t<item quantity=\"%v\">%v</item>\n", icu.OtherCase, w.format(message, 0))
+		} else {
+			plural := message[pluralIndex].(*icu.Select)
+			position := w.position(plural.Name)
+			before, after := w.format(message[:pluralIndex], 0), w.format(message[pluralIndex+1:], 0)
+			for _, c := range plural.Cases {
+				fmt.Fprintf(&b, "\t\t<item quantity=\"%v\">%v%v%v</item>\n", c.Key, before, w.format(c.Message, position), after)
+			}
+		}
+		b.WriteString("\t</plurals>\n")
+	}
+	write(nil)
+	return b.String()
+}
+
+// androidMessageSelects returns the select arguments of an ICU message and the cases of each of them used in any
+// language, "other" case goes last.
+func androidMessageSelects(args []icu.Arg, messages map[goloc.Lang]icu.Message) (selects []string, cases [][]string) {
+	used := map[string]map[string]bool{}
+	var collect func(m icu.Message)
+	collect = func(m icu.Message) {
+		for _, n := range m {
+			if s, ok := n.(*icu.Select); ok {
+				if s.Type == icu.TypeSelect {
+					if used[s.Name] == nil {
+						used[s.Name] = map[string]bool{}
+					}
+					for _, c := range s.Cases {
+						used[s.Name][c.Key] = true
+					}
+				}
+				for _, c := range s.Cases {
+					collect(c.Message)
+				}
+			}
+		}
+	}
+	for _, m := range messages {
+		collect(m)
+	}
+
+	for _, arg := range args {
+		if arg.Type != icu.TypeSelect {
+			continue
+		}
+		var argCases []string
+		for c := range used[arg.Name] {
+			if c != icu.OtherCase {
+				argCases = append(argCases, c)
+			}
+		}
+		sort.Strings(argCases)
+		selects = append(selects, arg.Name)
+		cases = append(cases, append(argCases, icu.OtherCase))
+	}
+	return
+}
+
+// androidMessageQuantity returns a name of the plural argument of an ICU message or an empty string if there's none.
+func androidMessageQuantity(args []icu.Arg) string {
+	for _, arg := range args {
+		if arg.Type == icu.TypePlural {
+			return arg.Name
+		}
+	}
+	return ""
+}
+
+// androidMessageResourceName returns a name of the resource containing the ICU message variant for the given select
+// cases, e.g. "liked_post__female".
+func androidMessageResourceName(key goloc.Key, selection []string) string {
+	name := androidFieldName(key)
+	for _, c := range selection {
+		name += "__" + strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+				return r
+			}
+			return '_'
+		}, c)
+	}
+	return name
+}
+
+// androidFieldName returns a name of the R class field of a resource.
+func androidFieldName(key goloc.Key) string {
+	return strings.ReplaceAll(key, ".", "_")
+}
+
+// androidResolveSelects replaces select arguments of the message with the chosen cases ("other" if the case
+// isn't defined).
+func androidResolveSelects(message icu.Message, chosen map[string]string) (resolved icu.Message) {
+	for _, n := range message {
+		s, ok := n.(*icu.Select)
+		switch {
+		case !ok:
+			resolved = append(resolved, n)
+		case s.Type == icu.TypeSelect:
+			c := s.Case(chosen[s.Name])
+			if c == nil {
+				c = s.Case(icu.OtherCase)
+			}
+			resolved = append(resolved, androidResolveSelects(c.Message, chosen)...)
+		default:
+			resolvedSelect := &icu.Select{Name: s.Name, Type: s.Type, Offset: s.Offset}
+			for _, c := range s.Cases {
+				resolvedSelect.Cases = append(resolvedSelect.Cases, &icu.Case{Key: c.Key, Message: androidResolveSelects(c.Message, chosen)})
+			}
+			resolved = append(resolved, resolvedSelect)
+		}
+	}
+	return
+}
+
+// androidMessageWriter converts an ICU message without select arguments into a resource string. Arguments are
+// positional in the order of goloc.KeyMeta.MessageArgs.
+type androidMessageWriter struct {
+	args []icu.Arg
+}
+
+// format returns a resource string of the message. pluralPosition is a position of the plural argument "#" refers to.
+func (w *androidMessageWriter) format(message icu.Message, pluralPosition int) string {
+	var b strings.Builder
+	for _, n := range message {
+		switch n := n.(type) {
+		case *icu.Text:
+			b.WriteString(strings.ReplaceAll(goloc.WithReplacedSpecialChars(android{}, n.Value), "%", "%%"))
+		case *icu.Placeholder:
+			fmt.Fprintf(&b, "%%%v$s", w.position(n.Name))
+		case *icu.Pound:
+			fmt.Fprintf(&b, "%%%v$d", pluralPosition)
+		}
+	}
+	return b.String()
+}
+
+// position returns a 1-based position of the argument.
+func (w *androidMessageWriter) position(name string) int {
+	for i, arg := range w.args {
+		if arg.Name == name {
+			return i + 1
+		}
+	}
+	return 0
+}
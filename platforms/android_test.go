@@ -0,0 +1,53 @@
+package platforms
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/icu"
+	"github.com/s0nerik/goloc/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAndroidExtraFiles(t *testing.T) {
+	message, err := icu.Parse("{count, plural, one {# file} other {# files}}")
+	assert.NoError(t, err)
+	args := goloc.GenerateArgs{
+		ResDir: filepath.Join("app", "src", "main", "res"),
+		Meta: goloc.LocalizationMeta{
+			"title": {},
+			"files": {Messages: map[goloc.Lang]icu.Message{"en": message}, MessageArgs: message.Args()},
+		},
+	}
+
+	// The helpers are opt-in
+	p := &android{}
+	assert.NoError(t, p.Configure(registry.PlatformParams{}))
+	files, err := p.ExtraFiles(args)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	kotlinFile := filepath.Join("app", "src", "main", "java", "com", "example", "i18n", "LocalizedMessages.kt")
+	assert.NoError(t, p.Configure(registry.PlatformParams{"android-messages-package": "com.example.i18n", "android-messages-file": kotlinFile}))
+	files, err = p.ExtraFiles(args)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, kotlinFile, files[0].Path)
+		assert.Contains(t, files[0].Contents, "\npackage com.example.i18n\n")
+		assert.Contains(t, files[0].Contents, "\nfun Context.files(count: Int): String = ")
+	}
+
+	// The file is still written without ICU messages, so that it doesn't refer to the removed resources
+	files, err = p.ExtraFiles(goloc.GenerateArgs{ResDir: args.ResDir, Meta: goloc.LocalizationMeta{"title": {}}})
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, kotlinFile, files[0].Path)
+		assert.NotContains(t, files[0].Contents, "fun Context.")
+	}
+
+	assert.EqualError(t, p.Configure(registry.PlatformParams{"android-messages-package": "com.example.i18n"}), `"--android-messages-file" must be specified along with "--android-messages-package"`)
+	assert.EqualError(t, p.Configure(registry.PlatformParams{"android-messages-file": kotlinFile}), `"--android-messages-package" must be specified along with "--android-messages-file"`)
+	assert.EqualError(t, p.Configure(registry.PlatformParams{"android-messages-package": "com.package", "android-messages-file": kotlinFile}), `"com.package" is not a valid package name`)
+	assert.EqualError(t, p.Configure(registry.PlatformParams{"android-messages-package": "com..example", "android-messages-file": kotlinFile}), `"com..example" is not a valid package name`)
+}
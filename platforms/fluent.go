@@ -0,0 +1,259 @@
+package platforms
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/icu"
+	"github.com/s0nerik/goloc/registry"
+)
+
+func init() {
+	registry.RegisterPlatform(&fluent{})
+}
+
+var fluentFormatRegexp = regexp.MustCompile(`^(\$|[A-Z][A-Z0-9_-]*(\((.*)\))?)$`)
+var fluentInvalidIdentifierChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+var fluentIdentifierRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+var fluentTextReplacer = strings.NewReplacer(`{`, `{ "{" }`, `}`, `{ "}" }`)
+
+// fluent generates Project Fluent (https://projectfluent.org) resources.
+type fluent struct{}
+
+func (fluent) Names() []string {
+	return []string{
+		"fluent",
+		"Fluent",
+		"ftl",
+	}
+}
+
+func (fluent) LocalizationFilePath(lang goloc.Lang, namespace goloc.Namespace, resDir goloc.ResDir) string {
+	if namespace != "" {
+		return filepath.Join(resDir, lang, fmt.Sprintf("%s.ftl", namespace))
+	}
+	return filepath.Join(resDir, lang, "main.ftl")
+}
+
+func (fluent) Header(args *goloc.HeaderArgs) string {
+	return "### DO NOT EDIT. This file is generated via https://github.com/s0nerik/goloc\n\n"
+}
+
+// LocalizedString writes the ICU messages as Fluent select expressions (see fluentMessageWriter).
+func (fluent) LocalizedString(args *goloc.LocalizedStringArgs) string {
+	lines := strings.Split(args.Value, "\n")
+	for i, line := range lines {
+		lines[i] = fluentLine(line)
+	}
+
+	var b strings.Builder
+	if args.Description != "" {
+		for _, line := range strings.Split(args.Description, "\n") {
+			b.WriteString(strings.TrimRight(fmt.Sprintf("# %s", line), " ") + "\n")
+		}
+	}
+
+	id := fluentIdentifier(args.Key)
+	if args.Message != nil {
+		b.WriteString(fmt.Sprintf("%s =\n    %s\n", id, fluentMessageWriter{}.pattern(args.Message, "    ", "", true)))
+		return b.String()
+	}
+	if len(lines) == 1 {
+		b.WriteString(fmt.Sprintf("%s = %s\n", id, lines[0]))
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("%s =\n", id))
+	for _, line := range lines {
+		if line == "" {
+			b.WriteString("\n")
+		} else {
+			b.WriteString(fmt.Sprintf("    %s\n", line))
+		}
+	}
+	return b.String()
+}
+
+func (fluent) Footer(args *goloc.FooterArgs) string {
+	return ""
+}
+
+// ValidateFormat accepts either "$" (a plain variable reference) or a Fluent function name with optional
+// named arguments (e.g. "NUMBER" or "NUMBER(minimumFractionDigits: 2)").
+func (fluent) ValidateFormat(format string) error {
+	if !fluentFormatRegexp.MatchString(format) {
+		return errors.New(`format must be either "$" or a function name with optional arguments (e.g. "NUMBER(minimumFractionDigits: 2)")`)
+	}
+	return nil
+}
+
+// FormatString names variables after the arguments or, for the unnamed placeholders, after their formats (e.g. "$str"
+// and "$str2" for "{str} {str}"). Placeholders of the default "{}" format become "$argN" variables.
+func (fluent) FormatString(args *goloc.FormatStringArgs) string {
+	variable := fmt.Sprintf("$arg%d", args.Index)
+	if args.ArgName != "" {
+		variable = "$" + fluentIdentifier(args.ArgName)
+	} else if args.Name != "" {
+		variable = "$" + fluentIdentifier(args.Name)
+		if args.Occurrence > 1 {
+			variable += strconv.Itoa(args.Occurrence)
+		}
+	}
+
+	m := fluentFormatRegexp.FindStringSubmatch(args.Format)
+	if m == nil || m[1] == "$" {
+		return fmt.Sprintf("{ %s }", variable)
+	}
+
+	function := strings.TrimSuffix(m[1], m[2])
+	if options := strings.TrimSpace(m[3]); options != "" {
+		return fmt.Sprintf("{ %s(%s, %s) }", function, variable, options)
+	}
+	return fmt.Sprintf("{ %s(%s) }", function, variable)
+}
+
+func (fluent) InlineFormats() goloc.Formats {
+	return goloc.Formats{
+		goloc.InlineFormatString:   `$`,
+		goloc.InlineFormatNumber:   `NUMBER`,
+		goloc.InlineFormatInteger:  `NUMBER(maximumFractionDigits: 0)`,
+		goloc.InlineFormatDecimal:  `NUMBER`,
+		goloc.InlineFormatCurrency: `NUMBER(minimumFractionDigits: 2, maximumFractionDigits: 2)`,
+	}
+}
+
+// Preprocess makes sure that each key gets its own Fluent identifier (e.g. "foo.bar" and "foo-bar" keys would both be
+// converted into "foo-bar"), otherwise one message would silently override another.
+func (fluent) Preprocess(args goloc.PreprocessArgs) error {
+	return validateIdentifiers(args, fluentIdentifier)
+}
+
+// ValidateMessage accepts messages with argument names and select cases which are valid Fluent identifiers. Plural
+// offsets can't be expressed in Fluent, so they aren't supported.
+func (fluent) ValidateMessage(message icu.Message) error {
+	for _, arg := range message.Args() {
+		if !fluentIdentifierRegexp.MatchString(arg.Name) {
+			return fmt.Errorf(`"%v" argument: name must be a valid Fluent identifier`, arg.Name)
+		}
+	}
+	return validateFluentMessage(message)
+}
+
+func validateFluentMessage(message icu.Message) error {
+	for _, n := range message {
+		s, ok := n.(*icu.Select)
+		if !ok {
+			continue
+		}
+		if s.Offset != 0 {
+			return fmt.Errorf(`"%v" argument: offset isn't supported`, s.Name)
+		}
+		for _, c := range s.Cases {
+			if s.Type == icu.TypeSelect && !fluentIdentifierRegexp.MatchString(c.Key) {
+				return fmt.Errorf(`"%v" argument: "%v" case must be a valid Fluent identifier`, s.Name, c.Key)
+			}
+			if err := validateFluentMessage(c.Message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (fluent) ReplacementChars() map[string]string {
+	return map[string]string{}
+}
+
+// fluentIdentifier converts a string into a valid Fluent identifier (e.g. "settings.title" -> "settings-title").
+func fluentIdentifier(str string) string {
+	id := strings.Trim(fluentInvalidIdentifierChars.ReplaceAllString(str, "-"), "-")
+	if id == "" || !(id[0] >= 'a' && id[0] <= 'z' || id[0] >= 'A' && id[0] <= 'Z') {
+		id = "key-" + id
+	}
+	return id
+}
+
+// fluentLine guards characters which have a special meaning at the beginning of a Fluent pattern line.
+func fluentLine(line string) string {
+	if line == "" {
+		return line
+	}
+	switch line[0] {
+	case '[', '*', '.':
+		return fmt.Sprintf(`{ "%c" }%s`, line[0], line[1:])
+	}
+	return line
+}
+
+// fluentMessageWriter converts an ICU message into a Fluent pattern. Select, plural and selectordinal arguments become
+// select expressions with the "other" case as the default variant, e.g. "{ $count -> [one] … *[other] … }".
+type fluentMessageWriter struct{}
+
+// pattern returns a Fluent pattern of the message. Continuation lines are indented with indent, pound is a variable "#"
+// refers to. lineStart is true if the pattern starts at the beginning of a line.
+func (w fluentMessageWriter) pattern(message icu.Message, indent string, pound string, lineStart bool) string {
+	if len(message) == 0 {
+		return `{ "" }`
+	}
+	var b strings.Builder
+	for i, n := range message {
+		switch n := n.(type) {
+		case *icu.Text:
+			for j, line := range strings.Split(n.Value, "\n") {
+				line = fluentTextReplacer.Replace(line)
+				if j > 0 {
+					b.WriteString("\n")
+					if line != "" {
+						b.WriteString(indent)
+					}
+				}
+				if j > 0 || i == 0 && lineStart {
+					line = fluentLine(line)
+				}
+				b.WriteString(line)
+			}
+		case *icu.Placeholder:
+			if n.Type == "number" {
+				fmt.Fprintf(&b, "{ NUMBER($%s) }", n.Name)
+			} else {
+				fmt.Fprintf(&b, "{ $%s }", n.Name)
+			}
+		case *icu.Pound:
+			fmt.Fprintf(&b, "{ %s }", pound)
+		case *icu.Select:
+			b.WriteString(w.selectExpression(n, indent, pound))
+		}
+	}
+	return b.String()
+}
+
+// selectExpression returns a Fluent select expression choosing the case of a select, plural or selectordinal argument.
+// Explicit values (e.g. "=0") become numeric variant keys (e.g. "[0]").
+func (w fluentMessageWriter) selectExpression(s *icu.Select, indent string, pound string) string {
+	selector := "$" + s.Name
+	switch s.Type {
+	case icu.TypePlural:
+		pound = selector
+	case icu.TypeSelectOrdinal:
+		pound = selector
+		selector = fmt.Sprintf(`NUMBER(%s, type: "ordinal")`, selector)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "{ %s ->\n", selector)
+	for _, c := range s.Cases {
+		prefix := indent + "    "
+		if c.Key == icu.OtherCase {
+			prefix = indent + "   *"
+		}
+		fmt.Fprintf(&b, "%s[%s] %s\n", prefix, strings.TrimPrefix(c.Key, "="), w.pattern(c.Message, indent+"    ", pound, false))
+	}
+	fmt.Fprintf(&b, "%s}", indent)
+	return b.String()
+}
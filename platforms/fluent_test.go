@@ -0,0 +1,135 @@
+package platforms
+
+import (
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/icu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFluentIdentifiers(t *testing.T) {
+	for key, expected := range map[string]string{
+		"title":                 "title",
+		"settings.screen_title": "settings-screen_title",
+		"my key!":               "my-key",
+		"1st":                   "key-1st",
+		"...":                   "key-",
+	} {
+		assert.Equal(t, expected, fluentIdentifier(key), key)
+	}
+}
+
+func TestFluentPreprocessIdentifierCollision(t *testing.T) {
+	err := fluent{}.Preprocess(goloc.PreprocessArgs{
+		Localizations: goloc.Localizations{"foo.bar": {"en": "A"}, "foo-bar": {"en": "B"}, "foo_bar": {"en": "C"}},
+		Meta: goloc.LocalizationMeta{
+			"foo.bar": {Cell: *goloc.NewCell("localizations", 2, 0)},
+			"foo-bar": {Cell: *goloc.NewCell("localizations", 3, 0)},
+			"foo_bar": {Cell: *goloc.NewCell("localizations", 4, 0)},
+		},
+		DefaultLocalization: "en",
+	})
+	assert.EqualError(t, err, `localizations!A2: "foo.bar" key is converted into "foo-bar" which is also used for "foo-bar" key (localizations!A3)`)
+
+	assert.NoError(t, fluent{}.Preprocess(goloc.PreprocessArgs{
+		Localizations: goloc.Localizations{"foo.bar": {"en": "A"}, "foo_bar": {"en": "C"}},
+		Meta: goloc.LocalizationMeta{
+			"foo.bar": {Cell: *goloc.NewCell("localizations", 2, 0)},
+			"foo_bar": {Cell: *goloc.NewCell("localizations", 4, 0)},
+		},
+		DefaultLocalization: "en",
+	}))
+}
+
+func TestFluentVariables(t *testing.T) {
+	loc, _, _, err := goloc.ParseLocalizations([][]goloc.RawCell{
+		{"key", "lang_en", "lang_de"},
+		{"twice", "{str} and {str}", "{str} und {str}"},
+		{"mixed", "{str} of {num}, {str}", "{str} von {num}, {str}"},
+		{"named", "{who:str} has {count:num} {str}", "{who:str} hat {count:num} {str}"},
+		{"default", "{} of {}", "{} von {}"},
+	}, fluent{}, goloc.Formats{"str": "$", "num": "NUMBER", "": "$"}, "main", "key", true, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, goloc.Localizations{
+		"twice":   {"en": "{ $str } and { $str2 }", "de": "{ $str } und { $str2 }"},
+		"mixed":   {"en": "{ $str } of { NUMBER($num) }, { $str2 }", "de": "{ $str } von { NUMBER($num) }, { $str2 }"},
+		"named":   {"en": "{ $who } has { NUMBER($count) } { $str }", "de": "{ $who } hat { NUMBER($count) } { $str }"},
+		"default": {"en": "{ $arg0 } of { $arg1 }", "de": "{ $arg0 } von { $arg1 }"},
+	}, loc)
+}
+
+func TestFluentFunctions(t *testing.T) {
+	for format, expected := range map[string]string{
+		"$":                                "{ $count }",
+		"NUMBER":                           "{ NUMBER($count) }",
+		"NUMBER(minimumFractionDigits: 2)": "{ NUMBER($count, minimumFractionDigits: 2) }",
+	} {
+		assert.NoError(t, fluent{}.ValidateFormat(format))
+		assert.Equal(t, expected, fluent{}.FormatString(&goloc.FormatStringArgs{Name: "x", Format: format, ArgName: "count"}))
+	}
+	assert.Error(t, fluent{}.ValidateFormat("number"))
+	assert.Error(t, fluent{}.ValidateFormat("%s"))
+}
+
+func TestFluentMultilineValues(t *testing.T) {
+	assert.Equal(t, "title = Title\n", fluent{}.LocalizedString(&goloc.LocalizedStringArgs{Key: "title", Value: "Title"}))
+	assert.Equal(t, "# Shown on top\n"+
+		"about =\n"+
+		"    First line\n"+
+		"\n"+
+		"    { \"*\" } starred\n"+
+		"    { \"[\" }bracket]\n",
+		fluent{}.LocalizedString(&goloc.LocalizedStringArgs{Key: "about", Value: "First line\n\n* starred\n[bracket]", Description: "Shown on top"}))
+}
+
+func fluentMessage(t *testing.T, key goloc.Key, str string) string {
+	message, err := icu.Parse(str)
+	assert.NoError(t, err)
+	assert.NoError(t, fluent{}.ValidateMessage(message))
+	return fluent{}.LocalizedString(&goloc.LocalizedStringArgs{Key: key, Value: str, Message: message})
+}
+
+func TestFluentMessages(t *testing.T) {
+	assert.Equal(t, "liked =\n"+
+		"    { $gender ->\n"+
+		"        [male] He\n"+
+		"       *[other] They\n"+
+		"    } liked { $count ->\n"+
+		"        [0] no posts\n"+
+		"        [one] { $count } post\n"+
+		"       *[other] { $count } posts\n"+
+		"    }\n",
+		fluentMessage(t, "liked", "{gender, select, male {He} other {They}} liked {count, plural, =0 {no posts} one {# post} other {# posts}}"))
+
+	assert.Equal(t, "place =\n"+
+		"    { NUMBER($n, type: \"ordinal\") ->\n"+
+		"        [one] { $n }st\n"+
+		"       *[other] { $n }th\n"+
+		"    } of { NUMBER($total) }\n",
+		fluentMessage(t, "place", "{n, selectordinal, one {#st} other {#th}} of {total, number}"))
+
+	// "#" in a nested select refers to the enclosing plural argument, empty cases and braces are quoted
+	assert.Equal(t, "files =\n"+
+		"    { $count ->\n"+
+		"        [zero] { \"\" }\n"+
+		"       *[other] { $kind ->\n"+
+		"            [image] { $count } images\n"+
+		"           *[other] { $count } { \"{\" }files{ \"}\" }\n"+
+		"        }\n"+
+		"    }\n",
+		fluentMessage(t, "files", "{count, plural, zero {} other {{kind, select, image {# images} other {# '{'files'}'}}}}"))
+}
+
+func TestFluentValidateMessage(t *testing.T) {
+	for str, expected := range map[string]string{
+		"{count, plural, offset:1 one {#} other {#}}":          `"count" argument: offset isn't supported`,
+		"{my.arg, select, other {x}}":                          `"my.arg" argument: name must be a valid Fluent identifier`,
+		"{gender, select, 1st {x} other {y}}":                  `"gender" argument: "1st" case must be a valid Fluent identifier`,
+		"{a, select, other {{b, plural, offset:2 other {#}}}}": `"b" argument: offset isn't supported`,
+	} {
+		message, err := icu.Parse(str)
+		assert.NoError(t, err, str)
+		assert.EqualError(t, fluent{}.ValidateMessage(message), expected, str)
+	}
+}
@@ -1,12 +1,15 @@
 package platforms
 
 import (
+	"errors"
 	"fmt"
 	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/icu"
 	"github.com/s0nerik/goloc/goloc/re"
 	"github.com/s0nerik/goloc/registry"
-	"io/ioutil"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -23,7 +26,8 @@ func (flutter) Names() []string {
 	}
 }
 
-func (flutter) LocalizationFilePath(lang goloc.Lang, resDir goloc.ResDir) string {
+// LocalizationFilePath ignores the namespace since all localized strings must belong to the same AppLocalizations class.
+func (flutter) LocalizationFilePath(lang goloc.Lang, namespace goloc.Namespace, resDir goloc.ResDir) string {
 	return filepath.Join(resDir, fmt.Sprintf("localizations_%s.g.dart", lang))
 }
 
@@ -46,7 +50,10 @@ class AppLocalizations%s implements AppLocalizations {
 }
 
 func (flutter) LocalizedString(args *goloc.LocalizedStringArgs) string {
-	if len(args.FormatArgs) > 0 {
+	if args.Message != nil {
+		w := &dartMessageWriter{lang: args.Lang}
+		return fmt.Sprintf("  String %s(%s) => %s;\n", args.Key, dartMessageParams(args.MessageArgs, true), w.expression(args.Message, ""))
+	} else if len(args.FormatArgs) > 0 {
 		fArgs := buildFormatArgsList(args.FormatArgs, nil)
 		return fmt.Sprintf("  String %s(%s) => sprintf(\"%s\", [%s]);\n", args.Key, fArgs, args.Value, fArgs)
 	} else {
@@ -54,8 +61,20 @@ func (flutter) LocalizedString(args *goloc.LocalizedStringArgs) string {
 	}
 }
 
+func (flutter) LocalizedArray(args *goloc.LocalizedStringArgs) string {
+	items := make([]string, len(args.Items))
+	for i, item := range args.Items {
+		items[i] = fmt.Sprintf("\"%s\"", item)
+	}
+	return fmt.Sprintf("  List<String> get %s => const [%s];\n", args.Key, strings.Join(items, ", "))
+}
+
 func (flutter) FallbackString(args *goloc.LocalizedStringArgs) string {
-	if len(args.FormatArgs) > 0 {
+	if args.IsArray {
+		return fmt.Sprintf("  List<String> get %s => fallback?.%s;\n", args.Key, args.Key)
+	} else if args.MessageArgs != nil {
+		return fmt.Sprintf("  String %s(%s) => fallback?.%s(%s);\n", args.Key, dartMessageParams(args.MessageArgs, true), args.Key, dartMessageParams(args.MessageArgs, false))
+	} else if len(args.FormatArgs) > 0 {
 		fArgs := buildFormatArgsList(args.FormatArgs, nil)
 		return fmt.Sprintf("  String %s(%s) => fallback?.%s(%s);\n", args.Key, fArgs, args.Key, fArgs)
 	} else {
@@ -75,6 +94,41 @@ func (flutter) ValidateFormat(format string) error {
 	return nil
 }
 
+// ValidateKey requires keys to be public Dart identifiers which don't clash with the AppLocalizations members.
+func (flutter) ValidateKey(key goloc.Key) error {
+	if err := validateDartIdentifier(key); err != nil {
+		return err
+	}
+	if key == "of" || key == "fallback" {
+		return fmt.Errorf(`"%s" is already a member of AppLocalizations`, key)
+	}
+	return nil
+}
+
+func (flutter) InlineFormats() goloc.Formats {
+	return goloc.Formats{
+		goloc.InlineFormatString:   `s`,
+		goloc.InlineFormatNumber:   `d`,
+		goloc.InlineFormatInteger:  `d`,
+		goloc.InlineFormatDecimal:  `f`,
+		goloc.InlineFormatCurrency: `.2f`,
+	}
+}
+
+// ValidateMessage accepts messages without selectordinal arguments since their cases are chosen by the generated
+// Dart code. Argument names must be valid Dart identifiers.
+func (flutter) ValidateMessage(message icu.Message) error {
+	for _, arg := range message.Args() {
+		if arg.Type == icu.TypeSelectOrdinal {
+			return fmt.Errorf(`"%v" argument: %v arguments aren't supported`, arg.Name, arg.Type)
+		}
+		if err := validateDartIdentifier(arg.Name); err != nil {
+			return fmt.Errorf(`"%v" argument: %v`, arg.Name, err)
+		}
+	}
+	return nil
+}
+
 func (flutter) ReplacementChars() map[string]string {
 	return map[string]string{
 		"\n": `\n`,
@@ -96,7 +150,7 @@ import 'dart:async';
 import 'dart:ui';
 
 import 'package:flutter/widgets.dart';
-import 'package:sprintf/sprintf.dart';
+%simport 'package:sprintf/sprintf.dart';
 
 %s
 abstract class AppLocalizations {
@@ -136,8 +190,19 @@ class AppLocalizationsDelegate extends LocalizationsDelegate<AppLocalizations> {
 	// Localized strings
 	var locBuilder strings.Builder
 	for _, key := range args.Localizations.SortedKeys() {
+		if m, ok := args.Meta[key]; ok && m.Description != "" {
+			for _, line := range strings.Split(m.Description, "\n") {
+				locBuilder.WriteString(strings.TrimRight("  /// "+line, " ") + "\n")
+			}
+		}
 		fArgs := args.FormatArgs[key]
-		if len(fArgs) <= 0 {
+		if m, ok := args.Meta[key]; ok && m.Items != nil {
+			str := fmt.Sprintf("  List<String> get %s;\n", key)
+			locBuilder.WriteString(str)
+		} else if ok && m.Messages != nil {
+			str := fmt.Sprintf("  String %s(%s);\n", key, dartMessageParams(m.MessageArgs, true))
+			locBuilder.WriteString(str)
+		} else if len(fArgs) <= 0 {
 			str := fmt.Sprintf("  String get %s;\n", key)
 			locBuilder.WriteString(str)
 		} else {
@@ -165,7 +230,17 @@ class AppLocalizationsDelegate extends LocalizationsDelegate<AppLocalizations> {
         return Future.value(AppLocalizations%s(null));
 `, strings.Title(args.DefaultLocalization)))
 
-	return fmt.Sprintf(contentFmt, partsBuilder.String(), locBuilder.String(), supportedLocales, loadBuilder.String())
+	// Plural arguments of the ICU messages are formatted by the intl package
+	intlImport := ""
+	for _, m := range args.Meta {
+		for _, arg := range m.MessageArgs {
+			if arg.Type == icu.TypePlural {
+				intlImport = "import 'package:intl/intl.dart';\n"
+			}
+		}
+	}
+
+	return fmt.Sprintf(contentFmt, intlImport, partsBuilder.String(), locBuilder.String(), supportedLocales, loadBuilder.String())
 }
 
 // buildFormatArgsList returns a ready-to-use list of format arguments for Dart.
@@ -198,8 +273,113 @@ func buildFormatArgsList(fArgs []goloc.FormatKey, formats goloc.Formats) string
 	return argsListBuilder.String()
 }
 
-func (flutter) Preprocess(args goloc.PreprocessArgs) (err error) {
-	locFileName := filepath.Join(args.ResDir, "localizations.dart")
-	err = ioutil.WriteFile(locFileName, []byte(LocalizationsContent(args)), 0644)
-	return
+// ExtraFiles returns the AppLocalizations class, so that it's written together with the localization files.
+func (flutter) ExtraFiles(args goloc.GenerateArgs) ([]goloc.OutputFile, error) {
+	content := LocalizationsContent(goloc.PreprocessArgs{
+		Localizations:       args.Localizations,
+		Formats:             args.Formats,
+		FormatArgs:          args.FormatArgs,
+		Meta:                args.Meta,
+		ResDir:              args.ResDir,
+		DefaultLocalization: args.DefaultLocalization,
+	})
+	return []goloc.OutputFile{{Path: filepath.Join(args.ResDir, "localizations.dart"), Contents: content}}, nil
+}
+
+var dartIdentifierRegexp = regexp.MustCompile(`^[a-zA-Z$][a-zA-Z0-9_$]*$`)
+
+var dartReservedWords = map[string]bool{
+	"assert": true, "break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"default": true, "do": true, "else": true, "enum": true, "extends": true, "false": true, "final": true,
+	"finally": true, "for": true, "if": true, "in": true, "is": true, "new": true, "null": true, "rethrow": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true, "try": true,
+	"var": true, "void": true, "while": true, "with": true,
+}
+
+// validateDartIdentifier returns an error if a key can't be used as a public Dart identifier.
+func validateDartIdentifier(key goloc.Key) error {
+	if !dartIdentifierRegexp.MatchString(key) {
+		return errors.New(`must start with a letter and contain only letters, digits, "_" and "$"`)
+	}
+	if dartReservedWords[key] {
+		return fmt.Errorf(`"%s" is a reserved word in Dart`, key)
+	}
+	return nil
+}
+
+// dartMessageParams returns a list of the ICU message parameters for Dart (with types if typed is true).
+func dartMessageParams(args []icu.Arg, typed bool) string {
+	var params []string
+	for _, arg := range args {
+		if !typed {
+			params = append(params, arg.Name)
+			continue
+		}
+		switch arg.Type {
+		case icu.TypeSelect:
+			params = append(params, "String "+arg.Name)
+		case icu.TypePlural, "number":
+			params = append(params, "num "+arg.Name)
+		default:
+			params = append(params, "Object "+arg.Name)
+		}
+	}
+	return strings.Join(params, ", ")
+}
+
+var dartStringReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\t", `\t`)
+
+// dartMessageWriter converts an ICU message into a Dart expression choosing the select and plural cases.
+type dartMessageWriter struct {
+	lang goloc.Lang
+}
+
+// expression returns a Dart string literal of the message. pound is an expression "#" is replaced with.
+func (w *dartMessageWriter) expression(message icu.Message, pound string) string {
+	var b strings.Builder
+	b.WriteString(`"`)
+	for _, n := range message {
+		switch n := n.(type) {
+		case *icu.Text:
+			b.WriteString(dartStringReplacer.Replace(n.Value))
+		case *icu.Placeholder:
+			fmt.Fprintf(&b, "${%s}", n.Name)
+		case *icu.Pound:
+			fmt.Fprintf(&b, "${%s}", pound)
+		case *icu.Select:
+			fmt.Fprintf(&b, "${%s}", w.selectExpression(n, pound))
+		}
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// selectExpression returns a Dart expression choosing the case of a select or plural argument.
+func (w *dartMessageWriter) selectExpression(s *icu.Select, pound string) string {
+	other := s.Case(icu.OtherCase)
+	if s.Type == icu.TypeSelect {
+		var cases []string
+		for _, c := range s.Cases {
+			if c != other {
+				cases = append(cases, fmt.Sprintf("'%s': %s", strings.ReplaceAll(c.Key, "'", `\'`), w.expression(c.Message, pound)))
+			}
+		}
+		return fmt.Sprintf("{%s}[%s] ?? %s", strings.Join(cases, ", "), s.Name, w.expression(other.Message, pound))
+	}
+
+	number := s.Name
+	if s.Offset != 0 {
+		number = fmt.Sprintf("(%s - %d)", s.Name, s.Offset)
+	}
+	var explicit strings.Builder
+	cases := []string{fmt.Sprintf("locale: '%s'", w.lang)}
+	for _, c := range s.Cases {
+		if strings.HasPrefix(c.Key, "=") {
+			value, _ := strconv.Atoi(strings.TrimPrefix(c.Key, "="))
+			fmt.Fprintf(&explicit, "%s == %d ? %s : ", s.Name, value, w.expression(c.Message, number))
+		} else {
+			cases = append(cases, fmt.Sprintf("%s: %s", c.Key, w.expression(c.Message, number)))
+		}
+	}
+	return fmt.Sprintf("%sIntl.pluralLogic(%s, %s)", explicit.String(), number, strings.Join(cases, ", "))
 }
@@ -0,0 +1,23 @@
+package platforms
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlutterExtraFiles(t *testing.T) {
+	files, err := flutter{}.ExtraFiles(goloc.GenerateArgs{
+		ResDir:              filepath.Join("lib", "l10n"),
+		Localizations:       goloc.Localizations{"title": {"en": "Title", "de": "Titel"}},
+		DefaultLocalization: "en",
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, filepath.Join("lib", "l10n", "localizations.dart"), files[0].Path)
+		assert.Contains(t, files[0].Contents, "class AppLocalizations")
+		assert.Contains(t, files[0].Contents, "title")
+	}
+}
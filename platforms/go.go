@@ -0,0 +1,244 @@
+package platforms
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/re"
+	"github.com/s0nerik/goloc/registry"
+)
+
+func init() {
+	registry.RegisterPlatform(&golang{})
+}
+
+// golang generates Go sources that register localized messages in a golang.org/x/text/message/catalog.Builder.
+type golang struct{}
+
+func (golang) Names() []string {
+	return []string{
+		"go",
+		"Go",
+		"golang",
+	}
+}
+
+func (golang) LocalizationFilePath(lang goloc.Lang, namespace goloc.Namespace, resDir goloc.ResDir) string {
+	if namespace != "" {
+		return filepath.Join(resDir, fmt.Sprintf("messages_%s_%s.g.go", goPackageName(namespace), lang))
+	}
+	return filepath.Join(resDir, fmt.Sprintf("messages_%s.g.go", lang))
+}
+
+func (golang) Header(args *goloc.HeaderArgs) string {
+	return fmt.Sprintf(`// Code generated by goloc (https://github.com/s0nerik/goloc). DO NOT EDIT.
+
+package %s
+
+import "golang.org/x/text/language"
+
+func init() {
+	tag := language.MustParse("%s")
+`, goPackageName(args.ResDir), args.Lang)
+}
+
+func (golang) LocalizedString(args *goloc.LocalizedStringArgs) string {
+	return fmt.Sprintf("\tsetString(tag, %s, \"%s\")\n", strconv.Quote(args.Key), args.Value)
+}
+
+func (golang) Footer(args *goloc.FooterArgs) string {
+	return "}\n"
+}
+
+func (golang) ValidateFormat(format string) error {
+	if strings.HasPrefix(format, `%`) {
+		return errors.New(`format must not start with "%" - it will be added automatically`)
+	}
+	return nil
+}
+
+func (golang) FormatString(args *goloc.FormatStringArgs) string {
+	return fmt.Sprintf(`%%[%d]%s`, args.Index+1, args.Format)
+}
+
+func (golang) InlineFormats() goloc.Formats {
+	return goloc.Formats{
+		goloc.InlineFormatString:   `s`,
+		goloc.InlineFormatNumber:   `d`,
+		goloc.InlineFormatInteger:  `d`,
+		goloc.InlineFormatDecimal:  `g`,
+		goloc.InlineFormatCurrency: `.2f`,
+	}
+}
+
+func (golang) ReplacementChars() map[string]string {
+	return map[string]string{
+		"\n": `\n`,
+		"\t": `\t`,
+		`"`:  `\"`,
+		`\`:  `\\`,
+		`%`:  `%%`,
+	}
+}
+
+// ValidateKey rejects keys which would be turned into the names that are already declared in the generated catalog.
+func (golang) ValidateKey(key goloc.Key) error {
+	if identifier := goIdentifier(key); goReservedIdentifiers[identifier] {
+		return fmt.Errorf(`"%s" is already declared in the generated catalog`, identifier)
+	}
+	return nil
+}
+
+// Preprocess makes sure that each key gets its own function in the generated catalog (e.g. "foo.bar" and "foo_bar"
+// keys would both be converted into "FooBar").
+func (golang) Preprocess(args goloc.PreprocessArgs) error {
+	return validateIdentifiers(args, goIdentifier)
+}
+
+// ExtraFiles returns the catalog, so that it's written together with the localization files.
+func (golang) ExtraFiles(args goloc.GenerateArgs) ([]goloc.OutputFile, error) {
+	content := goCatalogContent(goloc.PreprocessArgs{
+		Localizations:       args.Localizations,
+		Formats:             args.Formats,
+		FormatArgs:          args.FormatArgs,
+		Meta:                args.Meta,
+		ResDir:              args.ResDir,
+		DefaultLocalization: args.DefaultLocalization,
+	})
+	return []goloc.OutputFile{{Path: filepath.Join(args.ResDir, "messages.g.go"), Contents: content}}, nil
+}
+
+// goReservedIdentifiers are the names declared in the generated catalog.
+var goReservedIdentifiers = map[string]bool{
+	"DefaultLanguage": true,
+	"builder":         true,
+	"Catalog":         true,
+	"NewPrinter":      true,
+	"setString":       true,
+}
+
+func goCatalogContent(args goloc.PreprocessArgs) string {
+	contentFmt := `// Code generated by goloc (https://github.com/s0nerik/goloc). DO NOT EDIT.
+
+package %s
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// DefaultLanguage is the language used when there's no better match for the requested one.
+var DefaultLanguage = language.MustParse("%s")
+
+var builder = catalog.NewBuilder(catalog.Fallback(DefaultLanguage))
+
+// Catalog contains all generated localized messages.
+var Catalog catalog.Catalog = builder
+
+// NewPrinter returns a message printer for the given language which uses the generated messages.
+func NewPrinter(tag language.Tag) *message.Printer {
+	return message.NewPrinter(tag, message.Catalog(builder))
+}
+
+func setString(tag language.Tag, key string, msg string) {
+	if err := builder.SetString(tag, key, msg); err != nil {
+		panic(err)
+	}
+}
+%s`
+
+	var funcBuilder strings.Builder
+	for _, key := range args.Localizations.SortedKeys() {
+		fArgs := args.FormatArgs[key]
+		fallback := args.Localizations[key][args.DefaultLocalization]
+		if fallback == "" {
+			fallback = goloc.WithReplacedSpecialChars(golang{}, key)
+		}
+		funcBuilder.WriteString(fmt.Sprintf("\n// %s returns a localized %s string.\n", goIdentifier(key), strconv.Quote(key)))
+		if m, ok := args.Meta[key]; ok && m.Description != "" {
+			funcBuilder.WriteString("//\n")
+			for _, line := range strings.Split(m.Description, "\n") {
+				funcBuilder.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+			}
+		}
+		if len(fArgs) == 0 {
+			funcBuilder.WriteString(fmt.Sprintf("func %s(p *message.Printer) string {\n", goIdentifier(key)))
+			funcBuilder.WriteString(fmt.Sprintf("\treturn p.Sprintf(message.Key(%s, \"%s\"))\n}\n", strconv.Quote(key), fallback))
+		} else {
+			funcBuilder.WriteString(fmt.Sprintf("func %s(p *message.Printer, %s) string {\n", goIdentifier(key), buildGoFormatArgsList(fArgs, args.Formats)))
+			funcBuilder.WriteString(fmt.Sprintf("\treturn p.Sprintf(message.Key(%s, \"%s\"), %s)\n}\n", strconv.Quote(key), fallback, buildGoFormatArgsList(fArgs, nil)))
+		}
+	}
+
+	return fmt.Sprintf(contentFmt, goPackageName(args.ResDir), args.DefaultLocalization, funcBuilder.String())
+}
+
+// buildGoFormatArgsList returns a ready-to-use list of format arguments for Go.
+// If `formats` are specified - returns a list of typed parameters, otherwise - a list of argument names.
+func buildGoFormatArgsList(fArgs []goloc.FormatKey, formats goloc.Formats) string {
+	args := make([]string, len(fArgs))
+	for i, fKey := range fArgs {
+		args[i] = fmt.Sprintf("arg%v", i)
+		if formats == nil {
+			continue
+		}
+
+		valueType := "interface{}"
+		matches := re.SprintfRegexp().FindStringSubmatch("%" + formats[fKey])
+		if len(matches) >= 5 {
+			switch matches[5] {
+			case "s", "q":
+				valueType = "string"
+			case "d", "x", "X", "o", "O", "c":
+				valueType = "int"
+			case "e", "E", "f", "F", "g", "G":
+				valueType = "float64"
+			case "t":
+				valueType = "bool"
+			}
+		}
+		args[i] += " " + valueType
+	}
+	return strings.Join(args, ", ")
+}
+
+// goIdentifier converts a localized string key into an exported Go identifier (e.g. "settings.screen_title" -> "SettingsScreenTitle").
+func goIdentifier(key goloc.Key) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, part := range parts {
+		runes := []rune(part)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+
+	identifier := b.String()
+	if identifier == "" || !unicode.IsLetter([]rune(identifier)[0]) {
+		identifier = "Key" + identifier
+	}
+	return identifier
+}
+
+// goPackageName derives a Go package name from the resources directory name.
+func goPackageName(resDir goloc.ResDir) string {
+	name := strings.ToLower(filepath.Base(resDir))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		return "i18n"
+	}
+	return name
+}
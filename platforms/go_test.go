@@ -0,0 +1,64 @@
+package platforms
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoQuotesKeys(t *testing.T) {
+	key := `say "hi"\now`
+	line := golang{}.LocalizedString(&goloc.LocalizedStringArgs{Key: key, Value: `Hi`})
+	assert.Equal(t, "\tsetString(tag, \"say \\\"hi\\\"\\\\now\", \"Hi\")\n", line)
+
+	content := goCatalogContent(goloc.PreprocessArgs{
+		ResDir:              "i18n",
+		Localizations:       goloc.Localizations{key: {"en": `Hi`}},
+		DefaultLocalization: "en",
+	})
+	assert.Contains(t, content, "\n// SayHiNow returns a localized \"say \\\"hi\\\"\\\\now\" string.\n")
+	assert.Contains(t, content, "\treturn p.Sprintf(message.Key(\"say \\\"hi\\\"\\\\now\", \"Hi\"))\n")
+
+	content = goCatalogContent(goloc.PreprocessArgs{
+		ResDir:              "i18n",
+		Localizations:       goloc.Localizations{key: {"en": ``}},
+		DefaultLocalization: "en",
+	})
+	assert.Contains(t, content, "\treturn p.Sprintf(message.Key(\"say \\\"hi\\\"\\\\now\", \"say \\\"hi\\\"\\\\now\"))\n")
+}
+
+func TestGoValidateKey(t *testing.T) {
+	assert.NoError(t, golang{}.ValidateKey("title"))
+	assert.EqualError(t, golang{}.ValidateKey("catalog"), `"Catalog" is already declared in the generated catalog`)
+	assert.EqualError(t, golang{}.ValidateKey("new_printer"), `"NewPrinter" is already declared in the generated catalog`)
+	assert.EqualError(t, golang{}.ValidateKey("default.language"), `"DefaultLanguage" is already declared in the generated catalog`)
+}
+
+func TestGoPreprocessIdentifierCollision(t *testing.T) {
+	err := golang{}.Preprocess(goloc.PreprocessArgs{
+		ResDir:        "i18n",
+		Localizations: goloc.Localizations{"foo.bar": {"en": "A"}, "foo_bar": {"en": "B"}},
+		Meta: goloc.LocalizationMeta{
+			"foo.bar": {Cell: *goloc.NewCell("localizations", 2, 0)},
+			"foo_bar": {Cell: *goloc.NewCell("localizations", 3, 0)},
+		},
+		DefaultLocalization: "en",
+	})
+	assert.EqualError(t, err, `localizations!A3: "foo_bar" key is converted into "FooBar" which is also used for "foo.bar" key (localizations!A2)`)
+}
+
+func TestGoExtraFiles(t *testing.T) {
+	files, err := golang{}.ExtraFiles(goloc.GenerateArgs{
+		ResDir:              filepath.Join("internal", "i18n"),
+		Localizations:       goloc.Localizations{"title": {"en": "Title"}},
+		DefaultLocalization: "en",
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, filepath.Join("internal", "i18n", "messages.g.go"), files[0].Path)
+		assert.Contains(t, files[0].Contents, "package i18n\n")
+		assert.Contains(t, files[0].Contents, "\nfunc Title(p *message.Printer) string {\n")
+	}
+}
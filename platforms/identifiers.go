@@ -0,0 +1,20 @@
+package platforms
+
+import (
+	"fmt"
+
+	"github.com/s0nerik/goloc/goloc"
+)
+
+// validateIdentifiers makes sure that the keys are converted into distinct identifiers by a given function.
+func validateIdentifiers(args goloc.PreprocessArgs, identifier func(key goloc.Key) string) error {
+	keys := map[string]goloc.Key{}
+	for _, key := range args.Localizations.SortedKeys() {
+		id := identifier(key)
+		if other, ok := keys[id]; ok {
+			return fmt.Errorf(`%v: "%s" key is converted into "%s" which is also used for "%s" key (%v)`, args.Meta[key].Cell, key, id, other, args.Meta[other].Cell)
+		}
+		keys[id] = key
+	}
+	return nil
+}
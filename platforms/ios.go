@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/icu"
 	"github.com/s0nerik/goloc/registry"
 )
 
@@ -14,6 +17,28 @@ func init() {
 	registry.RegisterPlatform(&ios{})
 }
 
+const (
+	iosRegionBegin = "/* goloc:begin (generated strings, do not edit) */"
+	iosRegionEnd   = "/* goloc:end */"
+)
+
+var iosXMLReplacer = strings.NewReplacer(`&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`)
+
+var iosKeyRegexp = regexp.MustCompile(`(?m)^\s*"((?:[^"\\]|\\.)*)"\s*=`)
+
+var swiftIdentifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var swiftReservedWords = map[string]bool{
+	"associatedtype": true, "class": true, "deinit": true, "enum": true, "extension": true, "fileprivate": true,
+	"func": true, "import": true, "init": true, "inout": true, "internal": true, "let": true, "open": true,
+	"operator": true, "private": true, "precedencegroup": true, "protocol": true, "public": true, "rethrows": true,
+	"static": true, "struct": true, "subscript": true, "typealias": true, "var": true, "break": true, "case": true,
+	"catch": true, "continue": true, "default": true, "defer": true, "do": true, "else": true, "fallthrough": true,
+	"for": true, "guard": true, "if": true, "in": true, "repeat": true, "return": true, "throw": true,
+	"switch": true, "where": true, "while": true, "Any": true, "as": true, "false": true, "is": true, "nil": true,
+	"self": true, "Self": true, "super": true, "throws": true, "true": true, "try": true, "_": true,
+}
+
 type ios struct{}
 
 func (ios) Names() []string {
@@ -23,8 +48,11 @@ func (ios) Names() []string {
 	}
 }
 
-func (ios) LocalizationFilePath(lang goloc.Lang, resDir goloc.ResDir) string {
+func (ios) LocalizationFilePath(lang goloc.Lang, namespace goloc.Namespace, resDir goloc.ResDir) string {
 	fileName := "Localizable.strings"
+	if namespace != "" {
+		fileName = fmt.Sprintf("%v.strings", namespace)
+	}
 	targetDir := fmt.Sprintf("%v.lproj", lang)
 	if resDir != "" {
 		return filepath.Join(resDir, targetDir, fileName)
@@ -36,14 +64,74 @@ func (ios) Header(args *goloc.HeaderArgs) string {
 	return ""
 }
 
+// LocalizedString skips the ICU messages since they're written into the .stringsdict files (see ExtraFiles).
 func (ios) LocalizedString(args *goloc.LocalizedStringArgs) string {
-	return fmt.Sprintf("\"%v\" = \"%v\";\n", args.Key, args.Value)
+	if args.Message != nil {
+		return ""
+	}
+	str := fmt.Sprintf("\"%v\" = \"%v\";\n", args.Key, args.Value)
+	if args.Description != "" {
+		return fmt.Sprintf("/* %v */\n%v", strings.ReplaceAll(args.Description, "*/", "* /"), str)
+	}
+	return str
+}
+
+// LocalizedArray writes each item of the array as a separate "key[index]" string.
+func (ios) LocalizedArray(args *goloc.LocalizedStringArgs) string {
+	var b strings.Builder
+	if args.Description != "" {
+		fmt.Fprintf(&b, "/* %v */\n", strings.ReplaceAll(args.Description, "*/", "* /"))
+	}
+	for i, item := range args.Items {
+		fmt.Fprintf(&b, "\"%v[%v]\" = \"%v\";\n", args.Key, i, item)
+	}
+	return b.String()
 }
 
 func (ios) Footer(args *goloc.FooterArgs) string {
 	return ""
 }
 
+// Merge places generated strings into a marked region. Existing region is replaced, otherwise a new one is appended
+// to the end of the file.
+func (ios) Merge(args *goloc.MergeArgs) (merged string, conflicts []goloc.Key, err error) {
+	region := fmt.Sprintf("%v\n%v%v\n", iosRegionBegin, args.Strings, iosRegionEnd)
+
+	merged, ok := goloc.ReplaceRegion(args.Existing, iosRegionBegin, iosRegionEnd, region)
+	if !ok {
+		merged = args.Existing
+		if merged != "" && !strings.HasSuffix(merged, "\n") {
+			merged += "\n"
+		}
+		merged += region
+	}
+
+	manualKeys := map[string]bool{}
+	for _, m := range iosKeyRegexp.FindAllStringSubmatch(goloc.OutsideRegion(merged, iosRegionBegin, iosRegionEnd), -1) {
+		manualKeys[m[1]] = true
+	}
+	for _, key := range args.Keys {
+		if manualKeys[key] {
+			conflicts = append(conflicts, key)
+		}
+	}
+	return
+}
+
+// ValidateKey requires each "."-separated part of a key to be a Swift identifier, so that keys can be turned into
+// Swift accessors (e.g. "settings.title" into "L10n.Settings.title").
+func (ios) ValidateKey(key goloc.Key) error {
+	for _, part := range strings.Split(key, ".") {
+		if !swiftIdentifierRegexp.MatchString(part) {
+			return errors.New(`each "."-separated part must start with a letter or "_" and contain only letters, digits and "_"`)
+		}
+		if swiftReservedWords[part] {
+			return fmt.Errorf(`"%s" is a reserved word in Swift`, part)
+		}
+	}
+	return nil
+}
+
 func (ios) ValidateFormat(format string) error {
 	if strings.HasPrefix(format, `%`) {
 		return errors.New(`format must not start with "%" - it will be added automatically`)
@@ -52,9 +140,22 @@ func (ios) ValidateFormat(format string) error {
 }
 
 func (ios) FormatString(args *goloc.FormatStringArgs) string {
+	if args.Reordered {
+		return fmt.Sprintf(`%%%v$%v`, args.Index+1, args.Format)
+	}
 	return fmt.Sprintf(`%%%v`, args.Format)
 }
 
+func (ios) InlineFormats() goloc.Formats {
+	return goloc.Formats{
+		goloc.InlineFormatString:   `@`,
+		goloc.InlineFormatNumber:   `ld`,
+		goloc.InlineFormatInteger:  `ld`,
+		goloc.InlineFormatDecimal:  `f`,
+		goloc.InlineFormatCurrency: `.2f`,
+	}
+}
+
 func (ios) ReplacementChars() map[string]string {
 	return map[string]string {
 		`'`:  `\'`,
@@ -62,3 +163,143 @@ func (ios) ReplacementChars() map[string]string {
 		"\n": `\n`,
 	}
 }
+
+// ValidateMessage accepts messages which can be expressed with the .stringsdict plural rules: plural arguments without
+// an offset and with no explicit values other than "=0" (which becomes the "zero" rule). Select and selectordinal
+// arguments can't be expressed since .stringsdict files only support the cardinal plural rules.
+func (ios) ValidateMessage(message icu.Message) error {
+	for _, n := range message {
+		s, ok := n.(*icu.Select)
+		if !ok {
+			continue
+		}
+		switch s.Type {
+		case icu.TypeSelect:
+			return fmt.Errorf(`"%v" argument: select arguments aren't supported since .stringsdict files only support plural rules, use a separate key for each case instead`, s.Name)
+		case icu.TypeSelectOrdinal:
+			return fmt.Errorf(`"%v" argument: selectordinal arguments aren't supported since .stringsdict files only support cardinal plural rules`, s.Name)
+		}
+		if s.Offset != 0 {
+			return fmt.Errorf(`"%v" argument: offset isn't supported`, s.Name)
+		}
+		for _, c := range s.Cases {
+			if strings.HasPrefix(c.Key, "=") && (c.Key != "=0" || s.Case("zero") != nil) {
+				return fmt.Errorf(`"%v" argument: "%v" case isn't supported`, s.Name, c.Key)
+			}
+			if err := (ios{}).ValidateMessage(c.Message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ExtraFiles returns the ICU messages as .stringsdict files next to the .strings files of the same language and
+// namespace (e.g. "en.lproj/Localizable.stringsdict").
+func (ios) ExtraFiles(args goloc.GenerateArgs) ([]goloc.OutputFile, error) {
+	type stringsDict struct {
+		lang      goloc.Lang
+		namespace goloc.Namespace
+	}
+	messages := map[stringsDict][]goloc.Key{}
+	for key, m := range args.Meta {
+		for lang := range m.Messages {
+			file := stringsDict{lang, args.Namespaces[key]}
+			messages[file] = append(messages[file], key)
+		}
+	}
+
+	var files []goloc.OutputFile
+	for file, keys := range messages {
+		lang := file.lang
+		sort.Strings(keys)
+
+		var b strings.Builder
+		b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+		b.WriteString("<!DOCTYPE plist PUBLIC \"-//Apple//DTD PLIST 1.0//EN\" \"http://www.apple.com/DTDs/PropertyList-1.0.dtd\">\n")
+		b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+		for _, key := range keys {
+			m := args.Meta[key]
+			w := &iosStringsDictWriter{args: m.MessageArgs, names: map[string]bool{}}
+			format := w.format(m.Messages[lang], 0)
+
+			fmt.Fprintf(&b, "\t<key>%v</key>\n\t<dict>\n", iosXMLReplacer.Replace(key))
+			fmt.Fprintf(&b, "\t\t<key>NSStringLocalizedFormatKey</key>\n\t\t<string>%v</string>\n", format)
+			for i := 0; i < len(w.variables); i++ {
+				v := w.variables[i]
+				fmt.Fprintf(&b, "\t\t<key>%v</key>\n\t\t<dict>\n", v.name)
+				b.WriteString("\t\t\t<key>NSStringFormatSpecTypeKey</key>\n\t\t\t<string>NSStringPluralRuleType</string>\n")
+				b.WriteString("\t\t\t<key>NSStringFormatValueTypeKey</key>\n\t\t\t<string>ld</string>\n")
+				for _, c := range v.arg.Cases {
+					rule := c.Key
+					if rule == "=0" {
+						rule = "zero"
+					}
+					fmt.Fprintf(&b, "\t\t\t<key>%v</key>\n\t\t\t<string>%v</string>\n", rule, w.format(c.Message, v.position))
+				}
+				b.WriteString("\t\t</dict>\n")
+			}
+			b.WriteString("\t</dict>\n")
+		}
+		b.WriteString("</dict>\n</plist>\n")
+
+		filePath := strings.TrimSuffix(ios{}.LocalizationFilePath(lang, file.namespace, args.ResDir), ".strings") + ".stringsdict"
+		files = append(files, goloc.OutputFile{Path: filePath, Contents: b.String()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// iosStringsDictWriter converts an ICU message into a .stringsdict format string. Each plural argument becomes
+// a variable with the plural rules, arguments are positional in the order of goloc.KeyMeta.MessageArgs.
+type iosStringsDictWriter struct {
+	args      []icu.Arg
+	variables []iosStringsDictVariable
+	names     map[string]bool
+}
+
+type iosStringsDictVariable struct {
+	name     string
+	position int
+	arg      *icu.Select
+}
+
+// format returns a format string of the message. pluralPosition is a position of the plural argument "#" refers to.
+func (w *iosStringsDictWriter) format(message icu.Message, pluralPosition int) string {
+	var b strings.Builder
+	for _, n := range message {
+		switch n := n.(type) {
+		case *icu.Text:
+			b.WriteString(iosXMLReplacer.Replace(strings.ReplaceAll(n.Value, "%", "%%")))
+		case *icu.Placeholder:
+			if n.Type == "number" {
+				fmt.Fprintf(&b, "%%%v$ld", w.position(n.Name))
+			} else {
+				fmt.Fprintf(&b, "%%%v$@", w.position(n.Name))
+			}
+		case *icu.Pound:
+			fmt.Fprintf(&b, "%%%v$ld", pluralPosition)
+		case *icu.Select:
+			name := n.Name
+			for i := 2; w.names[name]; i++ {
+				name = fmt.Sprintf("%v_%v", n.Name, i)
+			}
+			w.names[name] = true
+			w.variables = append(w.variables, iosStringsDictVariable{name: name, position: w.position(n.Name), arg: n})
+			fmt.Fprintf(&b, "%%%v$#@%v@", w.position(n.Name), name)
+		}
+	}
+	return b.String()
+}
+
+// position returns a 1-based position of the argument.
+func (w *iosStringsDictWriter) position(name string) int {
+	for i, arg := range w.args {
+		if arg.Name == name {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package platforms
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/icu"
+	"github.com/stretchr/testify/assert"
+)
+
+func iosMessageMeta(t *testing.T, str string) *goloc.KeyMeta {
+	message, err := icu.Parse(str)
+	assert.NoError(t, err)
+	return &goloc.KeyMeta{Messages: map[goloc.Lang]icu.Message{"en": message}, MessageArgs: message.Args()}
+}
+
+func TestIOSExtraFiles(t *testing.T) {
+	files, err := ios{}.ExtraFiles(goloc.GenerateArgs{
+		ResDir: "res",
+		Meta: goloc.LocalizationMeta{
+			"title":       {},
+			"files":       iosMessageMeta(t, "{count, plural, one {# file} other {# files}}"),
+			"cart.items":  iosMessageMeta(t, "{count, plural, one {# item} other {# items}}"),
+			"cart.orders": iosMessageMeta(t, "{count, plural, one {# order} other {# orders}}"),
+		},
+		Namespaces: goloc.LocalizationNamespaces{"cart.items": "cart", "cart.orders": "cart"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, files, 2) {
+		assert.Equal(t, filepath.Join("res", "en.lproj", "Localizable.stringsdict"), files[0].Path)
+		assert.Contains(t, files[0].Contents, "<key>files</key>")
+		assert.NotContains(t, files[0].Contents, "<key>cart.items</key>")
+
+		assert.Equal(t, filepath.Join("res", "en.lproj", "cart.stringsdict"), files[1].Path)
+		assert.Contains(t, files[1].Contents, "<key>cart.items</key>")
+		assert.Contains(t, files[1].Contents, "<key>cart.orders</key>")
+		assert.NotContains(t, files[1].Contents, "<key>files</key>")
+	}
+
+	files, err = ios{}.ExtraFiles(goloc.GenerateArgs{ResDir: "res", Meta: goloc.LocalizationMeta{"title": {}}})
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestIOSValidateMessage(t *testing.T) {
+	message, err := icu.Parse("{count, plural, =0 {No files} one {# file} other {# files}}")
+	assert.NoError(t, err)
+	assert.NoError(t, ios{}.ValidateMessage(message))
+
+	message, err = icu.Parse("{gender, select, male {He} other {They}}")
+	assert.NoError(t, err)
+	assert.EqualError(t, ios{}.ValidateMessage(message), `"gender" argument: select arguments aren't supported since .stringsdict files only support plural rules, use a separate key for each case instead`)
+
+	message, err = icu.Parse("{n, selectordinal, one {#st} other {#th}}")
+	assert.NoError(t, err)
+	assert.EqualError(t, ios{}.ValidateMessage(message), `"n" argument: selectordinal arguments aren't supported since .stringsdict files only support cardinal plural rules`)
+}
+
+func TestIOSValidateKey(t *testing.T) {
+	for _, key := range []goloc.Key{"title", "settings.title", "_private", "item2", "Self_"} {
+		assert.NoError(t, ios{}.ValidateKey(key), key)
+	}
+
+	for _, key := range []goloc.Key{"my key", "my-key", "1st", "settings..title", ".title", "title.", "título"} {
+		assert.EqualError(t, ios{}.ValidateKey(key), `each "."-separated part must start with a letter or "_" and contain only letters, digits and "_"`, key)
+	}
+
+	assert.EqualError(t, ios{}.ValidateKey("default"), `"default" is a reserved word in Swift`)
+	assert.EqualError(t, ios{}.ValidateKey("settings.self"), `"self" is a reserved word in Swift`)
+}
+
+func TestIOSSelectMessagesAreReported(t *testing.T) {
+	// .stringsdict files can't choose a string by a select argument, so such messages are reported with the cell
+	_, _, _, err := goloc.ParseLocalizations([][]goloc.RawCell{
+		{"key", "lang_en"},
+		{"liked", "{gender, select, male {He} other {They}} liked it"},
+	}, ios{}, goloc.Formats{}, "main", "key", true, nil)
+	assert.EqualError(t, err, `main!B2: ICU message can't be written for platform "ios" ("gender" argument: select arguments aren't supported since .stringsdict files only support plural rules, use a separate key for each case instead)`)
+}
@@ -3,8 +3,10 @@ package platforms
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/goloc/icu"
 	"github.com/s0nerik/goloc/registry"
 )
 
@@ -21,7 +23,10 @@ func (json) Names() []string {
 	}
 }
 
-func (json) LocalizationFilePath(lang goloc.Lang, resDir goloc.ResDir) string {
+func (json) LocalizationFilePath(lang goloc.Lang, namespace goloc.Namespace, resDir goloc.ResDir) string {
+	if namespace != "" {
+		return filepath.Join(resDir, lang, fmt.Sprintf("%v.json", namespace))
+	}
 	return filepath.Join(resDir, fmt.Sprintf("%v.json", lang))
 }
 
@@ -36,6 +41,17 @@ func (json) LocalizedString(args *goloc.LocalizedStringArgs) string {
 	return fmt.Sprintf("\t\"%v\": \"%v\",\n", args.Key, args.Value)
 }
 
+func (json) LocalizedArray(args *goloc.LocalizedStringArgs) string {
+	items := make([]string, len(args.Items))
+	for i, item := range args.Items {
+		items[i] = fmt.Sprintf("\"%v\"", item)
+	}
+	if args.IsLast {
+		return fmt.Sprintf("\t\"%v\": [%v]\n", args.Key, strings.Join(items, ", "))
+	}
+	return fmt.Sprintf("\t\"%v\": [%v],\n", args.Key, strings.Join(items, ", "))
+}
+
 func (json) Footer(args *goloc.FooterArgs) string {
 	return "}"
 }
@@ -48,6 +64,21 @@ func (json) FormatString(args *goloc.FormatStringArgs) string {
 	return args.Format
 }
 
+func (json) InlineFormats() goloc.Formats {
+	return goloc.Formats{
+		goloc.InlineFormatString:   `%s`,
+		goloc.InlineFormatNumber:   `%d`,
+		goloc.InlineFormatInteger:  `%d`,
+		goloc.InlineFormatDecimal:  `%f`,
+		goloc.InlineFormatCurrency: `%.2f`,
+	}
+}
+
+// ValidateMessage accepts any ICU message since it's written as is.
+func (json) ValidateMessage(message icu.Message) error {
+	return nil
+}
+
 func (json) ReplacementChars() map[string]string {
 	return map[string]string{
 		"\n": `\n`,
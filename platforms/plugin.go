@@ -0,0 +1,244 @@
+package platforms
+
+import (
+	"bytes"
+	"context"
+	jsonenc "encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/registry"
+)
+
+func init() {
+	registry.RegisterPlatformFactory("plugin", newPluginPlatform, registry.PlatformParam{
+		Name:        "plugin-timeout",
+		Description: "Timeout for each call of the plugin executable.",
+		Default:     "1m",
+	})
+}
+
+// pluginRequest is sent to the plugin executable as JSON via stdin.
+type pluginRequest struct {
+	Command                 string                       `json:"command"`
+	Platform                string                       `json:"platform,omitempty"`
+	ResDir                  goloc.ResDir                 `json:"res_dir,omitempty"`
+	DefaultLocalization     goloc.Lang                   `json:"default_localization,omitempty"`
+	DefaultLocalizationPath string                       `json:"default_localization_path,omitempty"`
+	Localizations           goloc.Localizations          `json:"localizations,omitempty"`
+	Formats                 goloc.Formats                `json:"formats,omitempty"`
+	FormatArgs              goloc.LocalizationFormatArgs `json:"format_args,omitempty"`
+	Descriptions            map[goloc.Key]string         `json:"descriptions,omitempty"`
+	Namespaces              goloc.LocalizationNamespaces `json:"namespaces,omitempty"`
+	Hash                    string                       `json:"hash,omitempty"`
+}
+
+// pluginDescription is a plugin response to the "describe" command.
+type pluginDescription struct {
+	Names            []string          `json:"names"`
+	FormatString     string            `json:"format_string"`
+	FormatRegexp     string            `json:"format_regexp"`
+	ReplacementChars map[string]string `json:"replacement_chars"`
+}
+
+// pluginGenerateResponse is a plugin response to the "generate" command.
+type pluginGenerateResponse struct {
+	Files []goloc.OutputFile `json:"files"`
+}
+
+// pluginPlatform delegates generation of the localization files to an external executable.
+//
+// The executable receives a JSON request via stdin and must write a JSON response into stdout. There are two commands:
+// "describe" (called once before parsing) and "generate" (called with parsed localizations, formats and format arguments).
+type pluginPlatform struct {
+	path             string
+	timeout          time.Duration
+	names            []string
+	formatString     *template.Template
+	formatRegexp     *regexp.Regexp
+	replacementChars map[string]string
+
+	errMutex sync.Mutex
+	err      error
+}
+
+func newPluginPlatform(path string, params registry.PlatformParams) (goloc.Platform, error) {
+	timeout, err := time.ParseDuration(params["plugin-timeout"])
+	if err != nil {
+		return nil, fmt.Errorf(`invalid "--plugin-timeout" value: %w`, err)
+	}
+	p := &pluginPlatform{path: path, timeout: timeout}
+
+	var desc pluginDescription
+	if err := p.call(&pluginRequest{Command: "describe"}, &desc); err != nil {
+		return nil, err
+	}
+
+	p.names = desc.Names
+	if len(p.names) == 0 {
+		p.names = []string{"plugin"}
+	}
+
+	p.replacementChars = desc.ReplacementChars
+	if p.replacementChars == nil {
+		p.replacementChars = map[string]string{}
+	}
+
+	if desc.FormatString != "" {
+		if p.formatString, err = template.New("format_string").Funcs(templateFuncs).Parse(desc.FormatString); err != nil {
+			return nil, fmt.Errorf(`can't parse "format_string" template: %w`, err)
+		}
+		if err = p.formatString.Execute(&bytes.Buffer{}, &goloc.FormatStringArgs{}); err != nil {
+			return nil, fmt.Errorf(`can't execute "format_string" template: %w`, err)
+		}
+	}
+	if desc.FormatRegexp != "" {
+		if p.formatRegexp, err = regexp.Compile(desc.FormatRegexp); err != nil {
+			return nil, fmt.Errorf(`can't parse "format_regexp": %w`, err)
+		}
+	}
+
+	return p, nil
+}
+
+// call runs the plugin executable with a given request and decodes its response.
+func (p *pluginPlatform) call(request *pluginRequest, response interface{}) error {
+	input, err := jsonenc.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf(`plugin "%v" timed out after %v on "%v" command: %v`, p.path, p.timeout, request.Command, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf(`plugin "%v" failed on "%v" command (%w): %v`, p.path, request.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := jsonenc.Unmarshal(stdout.Bytes(), response); err != nil {
+		return fmt.Errorf(`can't parse plugin "%v" response to "%v" command: %w`, p.path, request.Command, err)
+	}
+
+	return nil
+}
+
+func (p *pluginPlatform) Generate(args goloc.GenerateArgs) ([]goloc.OutputFile, error) {
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	var resp pluginGenerateResponse
+	err := p.call(&pluginRequest{
+		Command:                 "generate",
+		Platform:                p.names[0],
+		ResDir:                  args.ResDir,
+		DefaultLocalization:     args.DefaultLocalization,
+		DefaultLocalizationPath: args.DefaultLocalizationPath,
+		Localizations:           args.Localizations,
+		Formats:                 args.Formats,
+		FormatArgs:              args.FormatArgs,
+		Descriptions:            pluginDescriptions(args.Meta),
+		Namespaces:              args.Namespaces,
+		Hash:                    args.Hash,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Files, nil
+}
+
+func (p *pluginPlatform) Names() []string {
+	return p.names
+}
+
+func (p *pluginPlatform) LocalizationFilePath(lang goloc.Lang, namespace goloc.Namespace, resDir goloc.ResDir) string {
+	return ""
+}
+
+func (p *pluginPlatform) Header(args *goloc.HeaderArgs) string {
+	return ""
+}
+
+func (p *pluginPlatform) LocalizedString(args *goloc.LocalizedStringArgs) string {
+	return ""
+}
+
+func (p *pluginPlatform) Footer(args *goloc.FooterArgs) string {
+	return ""
+}
+
+// ValidateFormat also makes sure that the "format_string" template can be executed with the format.
+func (p *pluginPlatform) ValidateFormat(format string) error {
+	if p.formatRegexp != nil && !p.formatRegexp.MatchString(format) {
+		return fmt.Errorf(`format must match "%v"`, p.formatRegexp)
+	}
+	if p.formatString != nil {
+		if err := p.formatString.Execute(&bytes.Buffer{}, &goloc.FormatStringArgs{Format: format}); err != nil {
+			return fmt.Errorf(`can't execute "format_string" template: %w`, err)
+		}
+	}
+	return nil
+}
+
+// FormatString renders the "format_string" template. Since it can't return errors, the first error is remembered
+// and reported by Err before the plugin is asked to generate the files.
+func (p *pluginPlatform) FormatString(args *goloc.FormatStringArgs) string {
+	if p.formatString == nil {
+		return args.Format
+	}
+
+	var buf bytes.Buffer
+	if err := p.formatString.Execute(&buf, args); err != nil {
+		p.fail(fmt.Errorf(`can't execute "format_string" template: %w`, err))
+		return args.Format
+	}
+	return buf.String()
+}
+
+func (p *pluginPlatform) fail(err error) {
+	p.errMutex.Lock()
+	defer p.errMutex.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *pluginPlatform) Err() error {
+	p.errMutex.Lock()
+	defer p.errMutex.Unlock()
+	return p.err
+}
+
+func (p *pluginPlatform) ReplacementChars() map[string]string {
+	return p.replacementChars
+}
+
+// pluginDescriptions collects non-empty descriptions of the localized strings.
+func pluginDescriptions(meta goloc.LocalizationMeta) map[goloc.Key]string {
+	descriptions := map[goloc.Key]string{}
+	for key, m := range meta {
+		if m.Description != "" {
+			descriptions[key] = m.Description
+		}
+	}
+	return descriptions
+}
@@ -0,0 +1,85 @@
+package platforms
+
+import (
+	jsonenc "encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePlugin(t *testing.T, dir string, script string) string {
+	path := filepath.Join(dir, "plugin.sh")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+	return path
+}
+
+func TestPluginTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writePlugin(t, dir, "echo 'working on it' >&2\nexec sleep 5\n")
+	_, err = registry.ResolvePlatform("plugin:"+path, registry.PlatformParams{"plugin-timeout": "100ms"})
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasSuffix(err.Error(), `timed out after 100ms on "describe" command: working on it`), err.Error())
+	}
+
+	_, err = registry.ResolvePlatform("plugin:"+path, registry.PlatformParams{"plugin-timeout": "soon"})
+	assert.Error(t, err)
+}
+
+func TestPluginFormatStringErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writePlugin(t, dir, `cat > /dev/null
+echo '{"names": ["p"], "format_string": "{{if eq (len .Format) 2}}{{.Format.Nope}}{{else if .ArgName}}{{.ArgName.Nope}}{{else}}%{{.Format}}{{end}}"}'
+`)
+	p, err := registry.ResolvePlatform("plugin:"+path, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.ValidateFormat("s"))
+	assert.Error(t, p.ValidateFormat("ld"))
+	assert.Equal(t, "%s", p.FormatString(&goloc.FormatStringArgs{Format: "s"}))
+	assert.NoError(t, p.(goloc.ErrorReporter).Err())
+
+	p.FormatString(&goloc.FormatStringArgs{Format: "s", ArgName: "count"})
+	assert.Error(t, p.(goloc.ErrorReporter).Err())
+	_, err = p.(goloc.Generator).Generate(goloc.GenerateArgs{})
+	assert.Equal(t, p.(goloc.ErrorReporter).Err(), err)
+}
+
+func TestPluginGenerateNamespaces(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The plugin returns the request it received as the contents of the generated file
+	path := writePlugin(t, dir, `request=$(cat)
+case "$request" in
+*'"describe"'*) echo '{"names": ["p"]}' ;;
+*) printf '{"files": [{"path": "request.json", "contents": %s}]}' "$(printf '%s' "$request" | sed 's/\\/\\\\/g; s/"/\\"/g; s/^/"/; s/$/"/')" ;;
+esac
+`)
+	p, err := registry.ResolvePlatform("plugin:"+path, nil)
+	assert.NoError(t, err)
+
+	files, err := p.(goloc.Generator).Generate(goloc.GenerateArgs{
+		Localizations: goloc.Localizations{"settings.title": {"en": "Settings"}, "ok": {"en": "OK"}},
+		Namespaces:    goloc.LocalizationNamespaces{"settings.title": "settings"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		var request pluginRequest
+		assert.NoError(t, jsonenc.Unmarshal([]byte(files[0].Contents), &request))
+		assert.Equal(t, goloc.LocalizationNamespaces{"settings.title": "settings"}, request.Namespaces)
+		assert.Equal(t, "generate", request.Command)
+	}
+}
@@ -0,0 +1,228 @@
+package platforms
+
+import (
+	"bytes"
+	jsonenc "encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/registry"
+)
+
+func init() {
+	registry.RegisterPlatformFactory("template", newTemplatePlatform)
+}
+
+// templateConfig represents a template platform configuration file.
+// Template paths are relative to the configuration file directory.
+type templateConfig struct {
+	Names            []string          `json:"names"`
+	FilePath         string            `json:"file_path"`
+	Header           string            `json:"header"`
+	Entry            string            `json:"entry"`
+	FallbackEntry    string            `json:"fallback_entry"`
+	Footer           string            `json:"footer"`
+	FormatString     string            `json:"format_string"`
+	ReplacementChars map[string]string `json:"replacement_chars"`
+	InlineFormats    goloc.Formats     `json:"inline_formats"`
+}
+
+// templateFilePathArgs encapsulates arguments to a localization file path template.
+type templateFilePathArgs struct {
+	Lang      goloc.Lang
+	Namespace goloc.Namespace
+	ResDir    goloc.ResDir
+}
+
+// templatePlatform is a platform defined by user-supplied text/template files.
+type templatePlatform struct {
+	names            []string
+	filePath         *template.Template
+	header           *template.Template
+	entry            *template.Template
+	fallbackEntry    *template.Template
+	footer           *template.Template
+	formatString     *template.Template
+	replacementChars map[string]string
+	inlineFormats    goloc.Formats
+
+	errMutex sync.Mutex
+	err      error
+}
+
+var templateFuncs = template.FuncMap{
+	"inc":        func(i int) int { return i + 1 },
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"title":      strings.Title,
+	"join":       strings.Join,
+	"replace":    strings.ReplaceAll,
+	"trimPrefix": strings.TrimPrefix,
+	"trimSuffix": strings.TrimSuffix,
+}
+
+func newTemplatePlatform(configPath string, params registry.PlatformParams) (goloc.Platform, error) {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf(`can't read template platform config: %w`, err)
+	}
+
+	var config templateConfig
+	if err := jsonenc.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf(`can't parse template platform config "%v": %w`, configPath, err)
+	}
+
+	if config.FilePath == "" {
+		return nil, fmt.Errorf(`"file_path" template must be specified in "%v"`, configPath)
+	}
+	if config.Entry == "" {
+		return nil, fmt.Errorf(`"entry" template must be specified in "%v"`, configPath)
+	}
+
+	p := &templatePlatform{
+		names:            config.Names,
+		replacementChars: config.ReplacementChars,
+		inlineFormats:    config.InlineFormats,
+	}
+	if len(p.names) == 0 {
+		p.names = []string{"template"}
+	}
+	if p.replacementChars == nil {
+		p.replacementChars = map[string]string{}
+	}
+
+	dir := filepath.Dir(configPath)
+	templateFiles := []struct {
+		name string
+		path string
+		dst  **template.Template
+	}{
+		{"file_path", config.FilePath, &p.filePath},
+		{"header", config.Header, &p.header},
+		{"entry", config.Entry, &p.entry},
+		{"fallback_entry", config.FallbackEntry, &p.fallbackEntry},
+		{"footer", config.Footer, &p.footer},
+	}
+	for _, f := range templateFiles {
+		if f.path == "" {
+			continue
+		}
+		if *f.dst, err = parseTemplateFile(f.name, filepath.Join(dir, f.path)); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.FormatString != "" {
+		if p.formatString, err = template.New("format_string").Funcs(templateFuncs).Parse(config.FormatString); err != nil {
+			return nil, fmt.Errorf(`can't parse "format_string" template: %w`, err)
+		}
+	}
+
+	return p, nil
+}
+
+func parseTemplateFile(name string, path string) (*template.Template, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(`can't read "%v" template: %w`, name, err)
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf(`can't parse "%v" template: %w`, name, err)
+	}
+	return t, nil
+}
+
+// execute renders a given template. Since platform methods can't return errors, the first error is remembered
+// and reported by Err before any file is written.
+func (p *templatePlatform) execute(t *template.Template, data interface{}) string {
+	if t == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		p.fail(fmt.Errorf(`can't render template: %w`, err))
+		return ""
+	}
+	return buf.String()
+}
+
+func (p *templatePlatform) fail(err error) {
+	p.errMutex.Lock()
+	defer p.errMutex.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *templatePlatform) Err() error {
+	p.errMutex.Lock()
+	defer p.errMutex.Unlock()
+	return p.err
+}
+
+func (p *templatePlatform) Names() []string {
+	return p.names
+}
+
+// LocalizationFilePath resolves the rendered path relative to the resources directory. Paths which already start with
+// the resources directory (e.g. "{{.ResDir}}/{{.Lang}}.php") are used as is, paths pointing outside of it are reported
+// as errors.
+func (p *templatePlatform) LocalizationFilePath(lang goloc.Lang, namespace goloc.Namespace, resDir goloc.ResDir) string {
+	path := strings.TrimSpace(p.execute(p.filePath, &templateFilePathArgs{Lang: lang, Namespace: namespace, ResDir: resDir}))
+	if path == "" {
+		return ""
+	}
+
+	rel := filepath.Clean(path)
+	if prefix := filepath.Clean(resDir) + string(filepath.Separator); strings.HasPrefix(rel, prefix) {
+		rel = strings.TrimPrefix(rel, prefix)
+	}
+	resolved, err := goloc.GeneratedFilePath(resDir, rel)
+	if err != nil {
+		p.fail(err)
+		return path
+	}
+	return resolved
+}
+
+func (p *templatePlatform) Header(args *goloc.HeaderArgs) string {
+	return p.execute(p.header, args)
+}
+
+func (p *templatePlatform) LocalizedString(args *goloc.LocalizedStringArgs) string {
+	return p.execute(p.entry, args)
+}
+
+func (p *templatePlatform) FallbackString(args *goloc.LocalizedStringArgs) string {
+	return p.execute(p.fallbackEntry, args)
+}
+
+func (p *templatePlatform) Footer(args *goloc.FooterArgs) string {
+	return p.execute(p.footer, args)
+}
+
+func (p *templatePlatform) ValidateFormat(format string) error {
+	return nil
+}
+
+func (p *templatePlatform) FormatString(args *goloc.FormatStringArgs) string {
+	if p.formatString == nil {
+		return args.Format
+	}
+	return p.execute(p.formatString, args)
+}
+
+func (p *templatePlatform) InlineFormats() goloc.Formats {
+	return p.inlineFormats
+}
+
+func (p *templatePlatform) ReplacementChars() map[string]string {
+	return p.replacementChars
+}
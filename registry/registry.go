@@ -1,15 +1,92 @@
 package registry
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
 	"github.com/s0nerik/goloc/goloc"
 )
 
+// PlatformFactory creates a platform given an argument specified after the factory prefix (e.g. "template:<argument>")
+// and values of the parameters declared along with the factory (see RegisterPlatformFactory).
+type PlatformFactory func(arg string, params PlatformParams) (goloc.Platform, error)
+
+// PlatformParam describes a single configuration parameter of a platform. Each parameter is exposed as a command line
+// flag of the generate command.
+type PlatformParam struct {
+	Name        string
+	Description string
+	Default     string
+}
+
+// PlatformParams represents values of the platform configuration parameters keyed by parameter name.
+type PlatformParams map[string]string
+
+// ConfigurablePlatform is implemented by platforms which accept configuration parameters.
+type ConfigurablePlatform interface {
+	// Returns configuration parameters supported by the platform.
+	Params() []PlatformParam
+
+	// Applies the parameter values before the platform is used.
+	Configure(params PlatformParams) error
+}
+
+// PlatformInterfaces returns goloc.PlatformInterfaces followed by the optional interfaces declared by the registry.
+func PlatformInterfaces() []reflect.Type {
+	interfaces := append([]reflect.Type{}, goloc.PlatformInterfaces...)
+	return append(interfaces, reflect.TypeOf((*ConfigurablePlatform)(nil)).Elem())
+}
+
 var platforms []goloc.Platform
+var platformFactories = map[string]PlatformFactory{}
+var platformFactoryParams = map[string][]PlatformParam{}
 
 func RegisterPlatform(p goloc.Platform) {
 	platforms = append(platforms, p)
 }
 
+// RegisterPlatformFactory registers a factory for platforms specified as "<prefix>:<argument>" along with
+// the configuration parameters of the created platforms.
+func RegisterPlatformFactory(prefix string, f PlatformFactory, params ...PlatformParam) {
+	platformFactories[prefix] = f
+	platformFactoryParams[prefix] = params
+}
+
+// PlatformFactoryParams returns configuration parameters of the platforms created by a factory with a given prefix.
+func PlatformFactoryParams(prefix string) []PlatformParam {
+	return platformFactoryParams[prefix]
+}
+
+// Platforms returns all registered platforms sorted by name.
+func Platforms() []goloc.Platform {
+	result := append([]goloc.Platform{}, platforms...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Names()[0] < result[j].Names()[0]
+	})
+	return result
+}
+
+// PlatformFactoryPrefixes returns sorted prefixes of all registered platform factories.
+func PlatformFactoryPrefixes() []string {
+	var result []string
+	for prefix := range platformFactories {
+		result = append(result, prefix)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// PlatformNames returns all names of the registered platforms.
+func PlatformNames() []string {
+	var names []string
+	for _, p := range platforms {
+		names = append(names, p.Names()...)
+	}
+	return names
+}
+
 func GetPlatform(name string) goloc.Platform {
 	for _, p := range platforms {
 		for _, n := range p.Names() {
@@ -20,3 +97,30 @@ func GetPlatform(name string) goloc.Platform {
 	}
 	return nil
 }
+
+// ResolvePlatform returns either a registered platform with a given name or a platform created by a factory
+// if the name is specified as "<prefix>:<argument>". Parameters which aren't specified get their default values.
+func ResolvePlatform(name string, params PlatformParams) (goloc.Platform, error) {
+	if p := GetPlatform(name); p != nil {
+		return p, nil
+	}
+
+	if i := strings.Index(name, ":"); i > 0 {
+		if f, ok := platformFactories[name[:i]]; ok {
+			factoryParams := PlatformParams{}
+			for _, param := range platformFactoryParams[name[:i]] {
+				factoryParams[param.Name] = param.Default
+				if value, ok := params[param.Name]; ok {
+					factoryParams[param.Name] = value
+				}
+			}
+			p, err := f(name[i+1:], factoryParams)
+			if err != nil {
+				return nil, fmt.Errorf(`can't create "%v" platform: %w`, name, err)
+			}
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf(`platform "%v" is not supported`, name)
+}
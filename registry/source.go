@@ -0,0 +1,114 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/s0nerik/goloc/goloc"
+)
+
+// SourceParam describes a single configuration parameter of a source. Each parameter is exposed as a command line flag.
+type SourceParam struct {
+	Name        string
+	Short       rune
+	Description string
+	Default     string
+	Required    bool
+	Bool        bool
+}
+
+// SourceParams represents values of the source configuration parameters keyed by parameter name.
+type SourceParams map[string]string
+
+// SourceFactory creates sources of a particular kind.
+type SourceFactory interface {
+	// Returns source names that can be used to select it.
+	Names() []string
+
+	// Returns a short human-readable description of the source.
+	Description() string
+
+	// Returns configuration parameters supported by the source.
+	Params() []SourceParam
+
+	// Returns a new source. Argument is a part of the source name after ":" (e.g. "exec:<argument>"), if any.
+	// Required parameters are guaranteed to be non-empty at this point.
+	New(arg string, params SourceParams) (goloc.Source, error)
+}
+
+// SourceIdentifier is implemented by source factories which can identify a document fetched by a source without creating
+// it (e.g. by a spreadsheet ID).
+type SourceIdentifier interface {
+	// Returns an ID of the document that a source created with the same argument and parameters would fetch.
+	ID(arg string, params SourceParams) string
+}
+
+var sourceFactories []SourceFactory
+
+func RegisterSource(f SourceFactory) {
+	sourceFactories = append(sourceFactories, f)
+}
+
+// Sources returns all registered source factories sorted by name.
+func Sources() []SourceFactory {
+	result := append([]SourceFactory{}, sourceFactories...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Names()[0] < result[j].Names()[0]
+	})
+	return result
+}
+
+func getSourceFactory(name string) SourceFactory {
+	for _, f := range sourceFactories {
+		for _, n := range f.Names() {
+			if n == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveSource validates the parameters and creates a source given its name, specified either as "<name>" or
+// "<name>:<argument>".
+func ResolveSource(name string, params SourceParams) (goloc.Source, error) {
+	f, arg := getSourceFactoryWithArg(name)
+	if f == nil {
+		return nil, fmt.Errorf(`"%v" is not a supported source`, name)
+	}
+
+	for _, p := range f.Params() {
+		if p.Required && strings.TrimSpace(params[p.Name]) == "" {
+			return nil, fmt.Errorf(`"--%v" parameter must be specified for "%v" source`, p.Name, f.Names()[0])
+		}
+	}
+
+	s, err := f.New(arg, params)
+	if err != nil {
+		return nil, fmt.Errorf(`can't create "%v" source: %w`, f.Names()[0], err)
+	}
+	return s, nil
+}
+
+// SourceID returns an ID of the document fetched by a source given its name and parameters without creating the source,
+// or "" if the source doesn't support identifying its documents (see SourceIdentifier).
+func SourceID(name string, params SourceParams) string {
+	f, arg := getSourceFactoryWithArg(name)
+	if i, ok := f.(SourceIdentifier); ok {
+		return i.ID(arg, params)
+	}
+	return ""
+}
+
+// getSourceFactoryWithArg returns a source factory given a source name specified either as "<name>" or
+// "<name>:<argument>", along with the argument.
+func getSourceFactoryWithArg(name string) (f SourceFactory, arg string) {
+	if f = getSourceFactory(name); f != nil {
+		return
+	}
+	if i := strings.Index(name, ":"); i > 0 {
+		return getSourceFactory(name[:i]), name[i+1:]
+	}
+	return
+}
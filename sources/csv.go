@@ -2,10 +2,37 @@ package sources
 
 import (
 	"encoding/csv"
-	"github.com/s0nerik/goloc/goloc"
 	"os"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/registry"
 )
 
+func init() {
+	registry.RegisterSource(&csvFactory{})
+}
+
+type csvFactory struct{}
+
+func (csvFactory) Names() []string {
+	return []string{"csv"}
+}
+
+func (csvFactory) Description() string {
+	return "Local CSV files."
+}
+
+func (csvFactory) Params() []registry.SourceParam {
+	return []registry.SourceParam{
+		{Name: "localizations-file-path", Description: "Localizations file path.", Required: true},
+		{Name: "formats-file-path", Description: "Formats file path. Can be omitted if only the inline typed placeholders are used."},
+	}
+}
+
+func (csvFactory) New(arg string, params registry.SourceParams) (goloc.Source, error) {
+	return CSV(params["localizations-file-path"], params["formats-file-path"]), nil
+}
+
 type csvSource struct {
 	localizationsFilePath string
 	formatsFilePath       string
@@ -37,6 +64,9 @@ func (s csvSource) LocalizationsDocumentName() string {
 }
 
 func (s csvSource) Formats() ([][]goloc.RawCell, error) {
+	if s.formatsFilePath == "" {
+		return nil, nil
+	}
 	return readCsv(s.formatsFilePath)
 }
 
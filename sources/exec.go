@@ -0,0 +1,179 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/registry"
+	"github.com/s0nerik/goloc/utils"
+)
+
+func init() {
+	registry.RegisterSource(&execFactory{})
+}
+
+const (
+	ExecOutputJSON = `json`
+	ExecOutputCSV  = `csv`
+)
+
+type execFactory struct{}
+
+func (execFactory) Names() []string {
+	return []string{"exec"}
+}
+
+func (execFactory) Description() string {
+	return `Output of an external command, specified as "exec:<command>".`
+}
+
+func (execFactory) Params() []registry.SourceParam {
+	return []registry.SourceParam{
+		{Name: "exec-output", Description: `Output format of the command ("json" or "csv").`, Default: ExecOutputJSON},
+		{Name: "exec-timeout", Description: `Timeout for the command.`, Default: "1m"},
+	}
+}
+
+func (execFactory) ID(arg string, params registry.SourceParams) string {
+	return strings.TrimSpace(arg)
+}
+
+func (execFactory) New(arg string, params registry.SourceParams) (goloc.Source, error) {
+	timeout, err := time.ParseDuration(params["exec-timeout"])
+	if err != nil {
+		return nil, fmt.Errorf(`invalid "--exec-timeout" value: %w`, err)
+	}
+	source, err := Exec(arg, params["exec-output"], timeout)
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// execOutput represents a JSON output of the source command.
+type execOutput struct {
+	Formats       [][]goloc.RawCell `json:"formats"`
+	Localizations [][]goloc.RawCell `json:"localizations"`
+}
+
+// execSource runs an external command and treats its output as localizations and formats tables.
+//
+// If output is "json", the command is executed once and must print an object with "formats" and "localizations"
+// tables (arrays of rows of strings). If output is "csv", the command is executed twice with an additional
+// "formats" or "localizations" argument and must print the corresponding table in CSV format.
+type execSource struct {
+	commandLine string
+	command     []string
+	output      string
+	timeout     time.Duration
+
+	once       sync.Once
+	jsonOutput execOutput
+	jsonErr    error
+}
+
+func Exec(command string, output string, timeout time.Duration) (*execSource, error) {
+	cmd, err := utils.SplitCommand(command)
+	if err != nil {
+		return nil, fmt.Errorf(`can't parse command: %w`, err)
+	}
+	if len(cmd) == 0 {
+		return nil, errors.New(`command must not be empty`)
+	}
+	if output != ExecOutputJSON && output != ExecOutputCSV {
+		return nil, fmt.Errorf(`unsupported command output format "%v", must be either "%v" or "%v"`, output, ExecOutputJSON, ExecOutputCSV)
+	}
+
+	return &execSource{
+		commandLine: strings.TrimSpace(command),
+		command:     cmd,
+		output:      output,
+		timeout:     timeout,
+	}, nil
+}
+
+func (s *execSource) run(args ...string) ([]byte, error) {
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	cmdArgs := append(append([]string{}, s.command[1:]...), args...)
+	cmd := exec.CommandContext(ctx, s.command[0], cmdArgs...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	cmdLine := strings.Join(append([]string{s.command[0]}, cmdArgs...), " ")
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf(`command "%v" timed out after %v: %v`, cmdLine, s.timeout, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf(`command "%v" failed (%w): %v`, cmdLine, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func (s *execSource) fetchJSON() (execOutput, error) {
+	s.once.Do(func() {
+		out, err := s.run()
+		if err != nil {
+			s.jsonErr = err
+			return
+		}
+		if err := json.Unmarshal(out, &s.jsonOutput); err != nil {
+			s.jsonErr = fmt.Errorf(`can't parse command output: %w`, err)
+		}
+	})
+	return s.jsonOutput, s.jsonErr
+}
+
+func (s *execSource) fetchCSV(table string) ([][]goloc.RawCell, error) {
+	out, err := s.run(table)
+	if err != nil {
+		return nil, err
+	}
+	return csv.NewReader(bytes.NewReader(out)).ReadAll()
+}
+
+// ID returns the command, so that snapshots of different commands aren't mixed up.
+func (s *execSource) ID() string {
+	return s.commandLine
+}
+
+func (s *execSource) FormatsDocumentName() string {
+	return `formats`
+}
+
+func (s *execSource) LocalizationsDocumentName() string {
+	return `localizations`
+}
+
+func (s *execSource) Formats() ([][]goloc.RawCell, error) {
+	if s.output == ExecOutputCSV {
+		return s.fetchCSV(`formats`)
+	}
+	out, err := s.fetchJSON()
+	return out.Formats, err
+}
+
+func (s *execSource) Localizations() ([][]goloc.RawCell, error) {
+	if s.output == ExecOutputCSV {
+		return s.fetchCSV(`localizations`)
+	}
+	out, err := s.fetchJSON()
+	return out.Localizations, err
+}
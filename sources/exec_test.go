@@ -0,0 +1,32 @@
+package sources
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecQuotedArguments(t *testing.T) {
+	source, err := Exec(`sh -c 'echo "{\"localizations\": [[\"key\", \"lang_en\"], [\"title\", \"$0\"]]}"' "My title"`, ExecOutputJSON, time.Minute)
+	assert.NoError(t, err)
+
+	rows, err := source.Localizations()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]goloc.RawCell{{"key", "lang_en"}, {"title", "My title"}}, rows)
+
+	_, err = Exec(`sh -c 'echo`, ExecOutputJSON, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestExecTimeout(t *testing.T) {
+	source, err := Exec(`sh -c 'echo "still fetching" >&2; exec sleep 5'`, ExecOutputJSON, 100*time.Millisecond)
+	assert.NoError(t, err)
+
+	_, err = source.Localizations()
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasSuffix(err.Error(), `timed out after 100ms: still fetching`), err.Error())
+	}
+}
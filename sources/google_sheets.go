@@ -3,43 +3,135 @@ package sources
 import (
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/s0nerik/goloc/goloc"
+	"github.com/s0nerik/goloc/registry"
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
 
+const (
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = 500 * time.Millisecond
+	maxBackoff            = 30 * time.Second
+)
+
+func init() {
+	registry.RegisterSource(&googleSheetsFactory{})
+}
+
+type googleSheetsFactory struct{}
+
+func (googleSheetsFactory) Names() []string {
+	return []string{"google_sheets"}
+}
+
+func (googleSheetsFactory) Description() string {
+	return "Google Sheets spreadsheet."
+}
+
+func (googleSheetsFactory) Params() []registry.SourceParam {
+	return []registry.SourceParam{
+		{Name: "spreadsheet", Short: 's', Description: "Spreadsheet ID.", Required: true},
+		{Name: "credentials", Short: 'c', Description: "Credentials to access a spreadsheet.", Default: "client_secret.json", Required: true},
+		{Name: "tab", Short: 't', Description: `Localizations tab name. Multiple tabs can be specified separated by commas, glob patterns (e.g. "strings_*") are supported.`, Default: "localizations", Required: true},
+		{Name: "formats-tab", Short: 'f', Description: "Formats tab name. Specify an empty value if only the inline typed placeholders are used.", Default: "formats"},
+		{Name: "value-render-option", Description: `How cell values are rendered ("FORMATTED_VALUE", "UNFORMATTED_VALUE" or "FORMULA").`, Default: "FORMATTED_VALUE"},
+		{Name: "date-time-render-option", Description: `How dates are rendered unless values are formatted ("FORMATTED_STRING" or "SERIAL_NUMBER").`, Default: "FORMATTED_STRING"},
+		{Name: "sheets-endpoint", Description: "Custom Google Sheets API endpoint (e.g. a local stand-in for testing)."},
+	}
+}
+
+func (googleSheetsFactory) ID(arg string, params registry.SourceParams) string {
+	return params["spreadsheet"]
+}
+
+func (googleSheetsFactory) New(arg string, params registry.SourceParams) (goloc.Source, error) {
+	var tabs []string
+	for _, tab := range strings.Split(params["tab"], ",") {
+		if tab = strings.TrimSpace(tab); tab != "" {
+			tabs = append(tabs, tab)
+		}
+	}
+
+	switch params["value-render-option"] {
+	case "FORMATTED_VALUE", "UNFORMATTED_VALUE", "FORMULA":
+	default:
+		return nil, fmt.Errorf(`unsupported "--value-render-option" value "%v"`, params["value-render-option"])
+	}
+	switch params["date-time-render-option"] {
+	case "FORMATTED_STRING", "SERIAL_NUMBER":
+	default:
+		return nil, fmt.Errorf(`unsupported "--date-time-render-option" value "%v"`, params["date-time-render-option"])
+	}
+
+	client, err := credentialsClient(params["credentials"])
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := GoogleSheets(client, params["sheets-endpoint"], params["spreadsheet"], params["formats-tab"], tabs)
+	if err != nil {
+		return nil, err
+	}
+	source.valueRenderOption = params["value-render-option"]
+	source.dateTimeRenderOption = params["date-time-render-option"]
+	return source, nil
+}
+
 type googleSheets struct {
-	sheetID          string
-	formatsTab       string
-	localizationsTab string
+	sheetID              string
+	formatsTab           string
+	localizationsTabs    []string
+	valueRenderOption    string
+	dateTimeRenderOption string
+	maxRetries           int
+	initialBackoff       time.Duration
 
 	sheetsAPI *sheets.SpreadsheetsService
+
+	once          sync.Once
+	formats       [][]goloc.RawCell
+	localizations []goloc.Tab
+	err           error
 }
 
+// GoogleSheets creates a source reading a spreadsheet using a given authorized HTTP client. If endpoint is empty,
+// the default Google Sheets API endpoint is used.
 func GoogleSheets(
-	credFilePath string,
+	client *http.Client,
+	endpoint string,
 	sheetID string,
 	formatsTab string,
-	localizationsTab string,
+	localizationsTabs []string,
 ) (*googleSheets, error) {
-	sheetsAPI, err := sheetsAPI(credFilePath)
+	sheetsAPI, err := sheetsAPI(client, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	return &googleSheets{
-		sheetID:          sheetID,
-		formatsTab:       formatsTab,
-		localizationsTab: localizationsTab,
-		sheetsAPI:        sheetsAPI,
+		sheetID:              sheetID,
+		formatsTab:           formatsTab,
+		localizationsTabs:    localizationsTabs,
+		valueRenderOption:    "FORMATTED_VALUE",
+		dateTimeRenderOption: "FORMATTED_STRING",
+		maxRetries:           defaultMaxRetries,
+		initialBackoff:       defaultInitialBackoff,
+		sheetsAPI:            sheetsAPI,
 	}, nil
 }
 
-func sheetsAPI(credFilePath string) (*sheets.SpreadsheetsService, error) {
-	ctx := context.Background()
-
+func credentialsClient(credFilePath string) (*http.Client, error) {
 	sec, err := ioutil.ReadFile(credFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read client secret file: %w", err)
@@ -50,7 +142,16 @@ func sheetsAPI(credFilePath string) (*sheets.SpreadsheetsService, error) {
 		return nil, fmt.Errorf("unable to parse client secret file to config: %w", err)
 	}
 
-	s, err := sheets.New(config.Client(ctx))
+	return config.Client(context.Background()), nil
+}
+
+func sheetsAPI(client *http.Client, endpoint string) (*sheets.SpreadsheetsService, error) {
+	opts := []option.ClientOption{option.WithHTTPClient(client)}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+
+	s, err := sheets.NewService(context.Background(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve Sheets Client: %w", err)
 	}
@@ -58,38 +159,184 @@ func sheetsAPI(credFilePath string) (*sheets.SpreadsheetsService, error) {
 	return s.Spreadsheets, nil
 }
 
-func fetchRawValues(api *sheets.SpreadsheetsService, sheetID string, tab string) ([][]interface{}, error) {
-	resp, err := api.Values.Get(sheetID, tab).Do()
+// withRetry executes a call, retrying it with an exponential backoff while the API responds with 429 or 5xx.
+func (s *googleSheets) withRetry(call func() error) error {
+	backoff := s.initialBackoff
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= s.maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func isRetryable(err error) bool {
+	if apiErr, ok := err.(*googleapi.Error); ok {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	return false
+}
+
+// resolveLocalizationsTabs returns the actual localizations tab names, expanding glob patterns (if any) using the
+// spreadsheet tab list.
+func (s *googleSheets) resolveLocalizationsTabs() ([]string, error) {
+	hasPatterns := false
+	for _, tab := range s.localizationsTabs {
+		if strings.ContainsAny(tab, `*?[`) {
+			hasPatterns = true
+		}
+	}
+	if !hasPatterns {
+		return s.localizationsTabs, nil
+	}
+
+	var spreadsheet *sheets.Spreadsheet
+	err := s.withRetry(func() (err error) {
+		spreadsheet, err = s.sheetsAPI.Get(s.sheetID).Fields("sheets.properties.title").Do()
+		return
+	})
 	if err != nil {
 		return nil, err
 	}
-	return resp.Values, nil
+
+	var tabs []string
+	added := map[string]bool{}
+	for _, pattern := range s.localizationsTabs {
+		matched := false
+		for _, sheet := range spreadsheet.Sheets {
+			title := sheet.Properties.Title
+			if ok, err := path.Match(pattern, title); err != nil {
+				return nil, fmt.Errorf(`invalid tab pattern "%v": %w`, pattern, err)
+			} else if ok && title != s.formatsTab {
+				matched = true
+				if !added[title] {
+					added[title] = true
+					tabs = append(tabs, title)
+				}
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf(`no tabs match "%v" pattern`, pattern)
+		}
+	}
+	return tabs, nil
 }
 
-func fetchRawStringValues(api *sheets.SpreadsheetsService, sheetID string, tab string) ([][]string, error) {
-	values, err := fetchRawValues(api, sheetID, tab)
-	result := make([][]string, len(values))
+// fetch loads the formats tab and all localizations tabs with a single batch request. The request is executed only
+// once, subsequent calls reuse its result.
+func (s *googleSheets) fetch() error {
+	s.once.Do(func() {
+		tabs, err := s.resolveLocalizationsTabs()
+		if err != nil {
+			s.err = err
+			return
+		}
+
+		var ranges []string
+		if s.formatsTab != "" {
+			ranges = append(ranges, sheetRange(s.formatsTab))
+		}
+		for _, tab := range tabs {
+			ranges = append(ranges, sheetRange(tab))
+		}
+
+		var resp *sheets.BatchGetValuesResponse
+		err = s.withRetry(func() (err error) {
+			resp, err = s.sheetsAPI.Values.BatchGet(s.sheetID).
+				Ranges(ranges...).
+				ValueRenderOption(s.valueRenderOption).
+				DateTimeRenderOption(s.dateTimeRenderOption).
+				Do()
+			return
+		})
+		if err != nil {
+			s.err = err
+			return
+		}
+		if len(resp.ValueRanges) != len(ranges) {
+			s.err = fmt.Errorf(`expected %v value ranges, got %v`, len(ranges), len(resp.ValueRanges))
+			return
+		}
+
+		valueRanges := resp.ValueRanges
+		if s.formatsTab != "" {
+			s.formats = stringValues(valueRanges[0].Values)
+			valueRanges = valueRanges[1:]
+		}
+		for i, tab := range tabs {
+			s.localizations = append(s.localizations, goloc.Tab{Name: tab, Rows: stringValues(valueRanges[i].Values)})
+		}
+	})
+	return s.err
+}
+
+// sheetRange returns an A1 notation range covering the whole tab.
+func sheetRange(tab string) string {
+	return `'` + strings.ReplaceAll(tab, `'`, `''`) + `'`
+}
+
+// stringValues converts raw cell values into strings. Unformatted values can be numbers or booleans.
+func stringValues(values [][]interface{}) [][]goloc.RawCell {
+	result := make([][]goloc.RawCell, len(values))
 	for i, row := range values {
-		result[i] = make([]string, len(row))
+		result[i] = make([]goloc.RawCell, len(row))
 		for j, col := range row {
-			result[i][j] = col.(string)
+			switch v := col.(type) {
+			case string:
+				result[i][j] = v
+			case float64:
+				result[i][j] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				result[i][j] = strconv.FormatBool(v)
+			case nil:
+				result[i][j] = ""
+			default:
+				result[i][j] = fmt.Sprint(v)
+			}
 		}
 	}
-	return result, err
+	return result
 }
 
-func (s googleSheets) FormatsDocumentName() string {
+func (s *googleSheets) FormatsDocumentName() string {
 	return s.formatsTab
 }
 
-func (s googleSheets) LocalizationsDocumentName() string {
-	return s.localizationsTab
+func (s *googleSheets) LocalizationsDocumentName() string {
+	return strings.Join(s.localizationsTabs, ",")
 }
 
-func (s googleSheets) Formats() ([][]goloc.RawCell, error) {
-	return fetchRawStringValues(s.sheetsAPI, s.sheetID, s.formatsTab)
+func (s *googleSheets) Formats() ([][]goloc.RawCell, error) {
+	if err := s.fetch(); err != nil {
+		return nil, err
+	}
+	return s.formats, nil
+}
+
+func (s *googleSheets) Localizations() ([][]goloc.RawCell, error) {
+	tabs, err := s.LocalizationTabs()
+	if err != nil {
+		return nil, err
+	}
+	if len(tabs) != 1 {
+		return nil, fmt.Errorf(`expected a single localizations tab, got %v`, len(tabs))
+	}
+	return tabs[0].Rows, nil
+}
+
+func (s *googleSheets) LocalizationTabs() ([]goloc.Tab, error) {
+	if err := s.fetch(); err != nil {
+		return nil, err
+	}
+	return s.localizations, nil
 }
 
-func (s googleSheets) Localizations() ([][]goloc.RawCell, error) {
-	return fetchRawStringValues(s.sheetsAPI, s.sheetID, s.localizationsTab)
+// ID returns the spreadsheet ID.
+func (s *googleSheets) ID() string {
+	return s.sheetID
 }
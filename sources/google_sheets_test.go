@@ -0,0 +1,109 @@
+package sources
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGoogleSheets(t *testing.T, handler http.HandlerFunc, tabs ...string) *googleSheets {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	source, err := GoogleSheets(server.Client(), server.URL+"/", "sheet", "formats", tabs)
+	assert.NoError(t, err)
+	source.initialBackoff = 0
+	return source
+}
+
+func writeBatchGetResponse(w http.ResponseWriter, values ...[][]interface{}) {
+	var ranges []map[string]interface{}
+	for _, v := range values {
+		ranges = append(ranges, map[string]interface{}{"values": v})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"valueRanges": ranges})
+}
+
+func TestGoogleSheetsBatchGet(t *testing.T) {
+	requests := 0
+	source := newTestGoogleSheets(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/v4/spreadsheets/sheet/values:batchGet", r.URL.Path)
+		assert.Equal(t, []string{"'formats'", "'strings'", "'it''s'"}, r.URL.Query()["ranges"])
+		assert.Equal(t, "UNFORMATTED_VALUE", r.URL.Query().Get("valueRenderOption"))
+		writeBatchGetResponse(w,
+			[][]interface{}{{"format", "ios"}, {"int", "d"}},
+			[][]interface{}{{"key", "lang_en"}, {"count", 42.5}, {"flag", true}},
+			[][]interface{}{{"key", "lang_en"}},
+		)
+	}, "strings", "it's")
+	source.valueRenderOption = "UNFORMATTED_VALUE"
+
+	formats, err := source.Formats()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]goloc.RawCell{{"format", "ios"}, {"int", "d"}}, formats)
+
+	tabs, err := source.LocalizationTabs()
+	assert.NoError(t, err)
+	assert.Equal(t, []goloc.Tab{
+		{Name: "strings", Rows: [][]goloc.RawCell{{"key", "lang_en"}, {"count", "42.5"}, {"flag", "true"}}},
+		{Name: "it's", Rows: [][]goloc.RawCell{{"key", "lang_en"}}},
+	}, tabs)
+
+	assert.Equal(t, 1, requests)
+}
+
+func TestGoogleSheetsWithoutFormatsTab(t *testing.T) {
+	source := newTestGoogleSheets(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"'strings'"}, r.URL.Query()["ranges"])
+		writeBatchGetResponse(w, [][]interface{}{{"key", "lang_en"}})
+	}, "strings")
+	source.formatsTab = ""
+
+	formats, err := source.Formats()
+	assert.NoError(t, err)
+	assert.Empty(t, formats)
+
+	tabs, err := source.LocalizationTabs()
+	assert.NoError(t, err)
+	assert.Equal(t, []goloc.Tab{{Name: "strings", Rows: [][]goloc.RawCell{{"key", "lang_en"}}}}, tabs)
+}
+
+func TestGoogleSheetsRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+		wantErr  bool
+	}{
+		{"rate limited", []int{429, 429}, 3, false},
+		{"server error", []int{503}, 2, false},
+		{"client error", []int{403}, 1, true},
+		{"retries exhausted", []int{500, 500, 500, 500, 500, 500}, 6, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			source := newTestGoogleSheets(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[requests-1])
+					return
+				}
+				writeBatchGetResponse(w, [][]interface{}{}, [][]interface{}{})
+			}, "strings")
+
+			_, err := source.Formats()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.requests, requests)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package sources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s0nerik/goloc/goloc"
+)
+
+// identifiableSource is implemented by sources which can identify a fetched document (e.g. by a spreadsheet ID).
+type identifiableSource interface {
+	ID() string
+}
+
+// snapshot represents raw tables fetched from a source at a specific time.
+type snapshot struct {
+	Source                    string            `json:"source"`
+	ID                        string            `json:"id,omitempty"`
+	FetchedAt                 time.Time         `json:"fetched_at"`
+	FormatsDocumentName       string            `json:"formats_document_name"`
+	LocalizationsDocumentName string            `json:"localizations_document_name"`
+	Formats                   [][]goloc.RawCell `json:"formats"`
+	Tabs                      []goloc.Tab       `json:"tabs"`
+}
+
+// SnapshotFilePath returns a path of the snapshot of a given source within cacheDir. Source argument (if any, e.g.
+// "exec:<command>") isn't included into the file name.
+func SnapshotFilePath(cacheDir string, sourceName string) string {
+	if i := strings.Index(sourceName, ":"); i > 0 {
+		sourceName = sourceName[:i]
+	}
+	return filepath.Join(cacheDir, fmt.Sprintf("%s.json", sourceName))
+}
+
+func readSnapshot(filePath string) (*snapshot, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf(`can't read snapshot: %w`, err)
+	}
+
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf(`can't parse snapshot "%v": %w`, filePath, err)
+	}
+	return &s, nil
+}
+
+func writeSnapshot(filePath string, s *snapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = goloc.WriteFileIfChanged(filePath, append(data, '\n'))
+	return err
+}
+
+// snapshotSource provides tables stored in a snapshot file.
+type snapshotSource struct {
+	filePath string
+	id       string
+
+	once     sync.Once
+	snapshot *snapshot
+	err      error
+}
+
+// Snapshot creates a source reading tables previously fetched from a named source and stored in cacheDir. If id is
+// specified (see registry.SourceID), the snapshot must have been fetched from the document with the same ID.
+func Snapshot(cacheDir string, sourceName string, id string) *snapshotSource {
+	return &snapshotSource{filePath: SnapshotFilePath(cacheDir, sourceName), id: id}
+}
+
+func (s *snapshotSource) load() (*snapshot, error) {
+	s.once.Do(func() {
+		s.snapshot, s.err = readSnapshot(s.filePath)
+		if s.err == nil && s.id != "" && s.snapshot.ID != s.id {
+			s.snapshot, s.err = nil, fmt.Errorf(`snapshot "%v" was fetched from "%v", not from "%v"`, s.filePath, s.snapshot.ID, s.id)
+		}
+		if s.err == nil {
+			log.Printf(`Using "%v" source snapshot fetched at %v`, s.filePath, s.snapshot.FetchedAt.Format(time.RFC3339))
+		}
+	})
+	return s.snapshot, s.err
+}
+
+func (s *snapshotSource) FormatsDocumentName() string {
+	if snap, err := s.load(); err == nil {
+		return snap.FormatsDocumentName
+	}
+	return `formats`
+}
+
+func (s *snapshotSource) LocalizationsDocumentName() string {
+	if snap, err := s.load(); err == nil {
+		return snap.LocalizationsDocumentName
+	}
+	return `localizations`
+}
+
+func (s *snapshotSource) Formats() ([][]goloc.RawCell, error) {
+	snap, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return snap.Formats, nil
+}
+
+func (s *snapshotSource) Localizations() ([][]goloc.RawCell, error) {
+	tabs, err := s.LocalizationTabs()
+	if err != nil {
+		return nil, err
+	}
+	if len(tabs) != 1 {
+		return nil, fmt.Errorf(`expected a single localizations tab, got %v`, len(tabs))
+	}
+	return tabs[0].Rows, nil
+}
+
+func (s *snapshotSource) LocalizationTabs() ([]goloc.Tab, error) {
+	snap, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return snap.Tabs, nil
+}
+
+// cachedSource stores tables fetched from a wrapped source into a snapshot file and falls back to that snapshot
+// if the source can't be reached due to a network failure.
+type cachedSource struct {
+	name   string
+	source goloc.Source
+
+	filePath string
+
+	once     sync.Once
+	snapshot *snapshot
+	err      error
+}
+
+// Cached wraps a named source so that its tables are stored in cacheDir after every successful fetch.
+func Cached(cacheDir string, sourceName string, source goloc.Source) *cachedSource {
+	return &cachedSource{
+		name:     sourceName,
+		source:   source,
+		filePath: SnapshotFilePath(cacheDir, sourceName),
+	}
+}
+
+func (s *cachedSource) fetch() (*snapshot, error) {
+	snap := &snapshot{
+		Source:                    s.name,
+		FetchedAt:                 time.Now().UTC().Truncate(time.Second),
+		FormatsDocumentName:       s.source.FormatsDocumentName(),
+		LocalizationsDocumentName: s.source.LocalizationsDocumentName(),
+	}
+	if source, ok := s.source.(identifiableSource); ok {
+		snap.ID = source.ID()
+	}
+
+	var err error
+	if snap.Formats, err = s.source.Formats(); err != nil {
+		return nil, err
+	}
+	if source, ok := s.source.(goloc.MultiTabSource); ok {
+		if snap.Tabs, err = source.LocalizationTabs(); err != nil {
+			return nil, err
+		}
+	} else {
+		rows, err := s.source.Localizations()
+		if err != nil {
+			return nil, err
+		}
+		snap.Tabs = []goloc.Tab{{Name: snap.LocalizationsDocumentName, Rows: rows}}
+	}
+	return snap, nil
+}
+
+func (s *cachedSource) load() (*snapshot, error) {
+	s.once.Do(func() {
+		snap, err := s.fetch()
+		if err == nil {
+			s.snapshot = snap
+			if err := writeSnapshot(s.filePath, snap); err != nil {
+				s.err = fmt.Errorf(`can't write snapshot: %w`, err)
+			}
+			return
+		}
+
+		var netErr net.Error
+		if !errors.As(err, &netErr) {
+			s.err = err
+			return
+		}
+
+		log.Printf(`Can't fetch "%v" source (%v), falling back to the snapshot`, s.name, err)
+		fallback := &snapshotSource{filePath: s.filePath}
+		if source, ok := s.source.(identifiableSource); ok {
+			fallback.id = source.ID()
+		}
+		s.snapshot, s.err = fallback.load()
+		if s.err != nil {
+			s.err = fmt.Errorf(`%w (%v)`, err, s.err)
+		}
+	})
+	return s.snapshot, s.err
+}
+
+func (s *cachedSource) FormatsDocumentName() string {
+	return s.source.FormatsDocumentName()
+}
+
+func (s *cachedSource) LocalizationsDocumentName() string {
+	return s.source.LocalizationsDocumentName()
+}
+
+func (s *cachedSource) Formats() ([][]goloc.RawCell, error) {
+	snap, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return snap.Formats, nil
+}
+
+func (s *cachedSource) Localizations() ([][]goloc.RawCell, error) {
+	tabs, err := s.LocalizationTabs()
+	if err != nil {
+		return nil, err
+	}
+	if len(tabs) != 1 {
+		return nil, fmt.Errorf(`expected a single localizations tab, got %v`, len(tabs))
+	}
+	return tabs[0].Rows, nil
+}
+
+func (s *cachedSource) LocalizationTabs() ([]goloc.Tab, error) {
+	snap, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return snap.Tabs, nil
+}
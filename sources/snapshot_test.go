@@ -0,0 +1,101 @@
+package sources
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/s0nerik/goloc/goloc"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	formats [][]goloc.RawCell
+	rows    [][]goloc.RawCell
+	err     error
+}
+
+func (s fakeSource) FormatsDocumentName() string       { return "formats" }
+func (s fakeSource) LocalizationsDocumentName() string { return "localizations" }
+
+func (s fakeSource) Formats() ([][]goloc.RawCell, error) { return s.formats, s.err }
+
+func (s fakeSource) Localizations() ([][]goloc.RawCell, error) { return s.rows, s.err }
+
+type fakeIdentifiableSource struct {
+	fakeSource
+	id string
+}
+
+func (s fakeIdentifiableSource) ID() string { return s.id }
+
+func TestCachedSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	online := fakeSource{
+		formats: [][]goloc.RawCell{{"format", "ios"}},
+		rows:    [][]goloc.RawCell{{"key", "lang_en"}, {"title", "Title"}},
+	}
+	tabs, err := Cached(dir, "exec:fetch", online).LocalizationTabs()
+	assert.NoError(t, err)
+	assert.Equal(t, []goloc.Tab{{Name: "localizations", Rows: online.rows}}, tabs)
+	assert.FileExists(t, filepath.Join(dir, "exec.json"))
+
+	offline := Snapshot(dir, "exec", "")
+	formats, err := offline.Formats()
+	assert.NoError(t, err)
+	assert.Equal(t, online.formats, formats)
+	rows, err := offline.Localizations()
+	assert.NoError(t, err)
+	assert.Equal(t, online.rows, rows)
+
+	unreachable := fakeSource{err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	rows, err = Cached(dir, "exec", unreachable).Localizations()
+	assert.NoError(t, err)
+	assert.Equal(t, online.rows, rows)
+
+	failing := fakeSource{err: errors.New("invalid credentials")}
+	_, err = Cached(dir, "exec", failing).Localizations()
+	assert.Error(t, err)
+
+	_, err = Cached(filepath.Join(dir, "empty"), "exec", unreachable).Localizations()
+	assert.Error(t, err)
+}
+
+func TestSnapshotIDMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	online := fakeIdentifiableSource{
+		fakeSource: fakeSource{rows: [][]goloc.RawCell{{"key", "lang_en"}, {"title", "Title"}}},
+		id:         "sheet-a",
+	}
+	_, err = Cached(dir, "google_sheets", online).Localizations()
+	assert.NoError(t, err)
+
+	rows, err := Snapshot(dir, "google_sheets", "sheet-a").Localizations()
+	assert.NoError(t, err)
+	assert.Equal(t, online.rows, rows)
+
+	_, err = Snapshot(dir, "google_sheets", "sheet-b").Localizations()
+	assert.EqualError(t, err, fmt.Sprintf(`snapshot "%v" was fetched from "sheet-a", not from "sheet-b"`, filepath.Join(dir, "google_sheets.json")))
+
+	unreachable := fakeIdentifiableSource{
+		fakeSource: fakeSource{err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+		id:         "sheet-b",
+	}
+	_, err = Cached(dir, "google_sheets", unreachable).Localizations()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `was fetched from "sheet-a", not from "sheet-b"`)
+
+	files, err := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
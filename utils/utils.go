@@ -1,5 +1,12 @@
 package utils
 
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
 // ColumnName returns a column name in Google Sheets format.
 func ColumnName(i uint) string {
 	return columnName("", i)
@@ -14,3 +21,104 @@ func columnName(orig string, i uint) string {
 	}
 	return columnName(columnName(orig, i/LettersNum-1), i%LettersNum)
 }
+
+// SnakeCase converts a string into snake_case (e.g. "My key-1" -> "my_key_1", "HTTPServer" -> "http_server").
+func SnakeCase(s string) string {
+	w := words(s)
+	for i := range w {
+		w[i] = strings.ToLower(w[i])
+	}
+	return strings.Join(w, "_")
+}
+
+// CamelCase converts a string into camelCase (e.g. "My key-1" -> "myKey1", "HTTPServer" -> "httpServer").
+func CamelCase(s string) string {
+	var b strings.Builder
+	for i, word := range words(s) {
+		word = strings.ToLower(word)
+		if i > 0 {
+			r, size := utf8.DecodeRuneInString(word)
+			word = string(unicode.ToUpper(r)) + word[size:]
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+// words splits a string into words separated by non-alphanumeric characters and case changes.
+func words(s string) (words []string) {
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextIsLower {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return
+}
+
+// SplitCommand splits a command line into arguments the way a POSIX shell does, without expanding anything:
+// arguments are separated by whitespace, single quotes preserve everything literally, double quotes and backslashes
+// outside of quotes escape the following character (e.g. `tool --name "My sheet" 'a b'` -> ["tool", "--name", "My sheet", "a b"]).
+func SplitCommand(command string) (args []string, err error) {
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' && r != '$' && r != '`' {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, errors.New(`unterminated quote or escape`)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
@@ -13,3 +13,40 @@ func TestColumnName(t *testing.T) {
 	assert.Equal(t, `AZ`, ColumnName(51))
 	assert.Equal(t, `BA`, ColumnName(52))
 }
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, `my_key_1`, SnakeCase(`my key-1`))
+	assert.Equal(t, `screen_title`, SnakeCase(`screenTitle`))
+	assert.Equal(t, `http_server`, SnakeCase(`HTTPServer`))
+	assert.Equal(t, `already_snake`, SnakeCase(`already_snake`))
+	assert.Equal(t, `page2_title`, SnakeCase(`Page2Title`))
+	assert.Equal(t, ``, SnakeCase(`--`))
+}
+
+func TestCamelCase(t *testing.T) {
+	assert.Equal(t, `myKey1`, CamelCase(`my key-1`))
+	assert.Equal(t, `screenTitle`, CamelCase(`screen_title`))
+	assert.Equal(t, `httpServer`, CamelCase(`HTTPServer`))
+	assert.Equal(t, `alreadyCamel`, CamelCase(`alreadyCamel`))
+	assert.Equal(t, `überKey`, CamelCase(`Über key`))
+	assert.Equal(t, ``, CamelCase(`--`))
+}
+
+func TestSplitCommand(t *testing.T) {
+	args, err := SplitCommand(`  tool --name "My sheet" 'a b' c\ d ""  `)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`tool`, `--name`, `My sheet`, `a b`, `c d`, ``}, args)
+
+	args, err = SplitCommand(`sh -c 'echo "$HOME"' "say \"hi\" \n" 'it'\''s'`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`sh`, `-c`, `echo "$HOME"`, `say "hi" \n`, `it's`}, args)
+
+	args, err = SplitCommand(`   `)
+	assert.Nil(t, err)
+	assert.Empty(t, args)
+
+	for _, bad := range []string{`tool "name`, `tool 'name`, `tool name\`} {
+		_, err = SplitCommand(bad)
+		assert.Error(t, err, bad)
+	}
+}